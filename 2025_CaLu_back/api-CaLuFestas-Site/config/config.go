@@ -0,0 +1,196 @@
+// Package config carrega a configuração da API em um struct, validado uma
+// vez na subida, em vez de cada pacote ler variáveis de ambiente por conta
+// própria.
+//
+// O perfil vem de APP_ENV (development, se vazio) e as fontes são aplicadas
+// nesta ordem, cada uma sobrepondo a anterior:
+//
+//  1. valores padrão;
+//  2. config.yaml (ou o arquivo em CONFIG_FILE) e config.<perfil>.yaml;
+//  3. .env e .env.<perfil>;
+//  4. variáveis de ambiente do processo.
+//
+// Um valor em branco não sobrepõe o das fontes anteriores. Arquivos ausentes
+// são ignorados, exceto o indicado em CONFIG_FILE.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const Production = "production"
+
+type Config struct {
+	Env            string      `yaml:"-"`
+	Port           string      `yaml:"port"`
+	Mongo          MongoConfig `yaml:"mongo"`
+	JWTSecret      string      `yaml:"jwt_secret"`
+	SMTP           SMTPConfig  `yaml:"smtp"`
+	RateLimitStore string      `yaml:"rate_limit_store"`
+	CEP            CEPConfig   `yaml:"cep"`
+	CORSOrigins    []string    `yaml:"cors_origins"`
+	// TrustedProxies lista IPs ou CIDRs dos proxies cujo X-Forwarded-For é
+	// aceito. Vazio: o IP do cliente é sempre o da conexão.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type MongoConfig struct {
+	URI string `yaml:"uri"`
+	DB  string `yaml:"db"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Email    string `yaml:"email"`
+	Password string `yaml:"password"`
+}
+
+type CEPConfig struct {
+	// Resolver "offline" evita chamadas externas em desenvolvimento
+	Resolver  string `yaml:"resolver"`
+	ViaCEPURL string `yaml:"viacep_url"`
+	// FixturesFile troca os CEPs embutidos do modo offline pelos do arquivo
+	FixturesFile string `yaml:"fixtures_file"`
+}
+
+func padrao() Config {
+	return Config{
+		Port:           "8080",
+		RateLimitStore: "memory",
+		CEP:            CEPConfig{Resolver: "viacep"},
+		// CaluFestas-Site (5173) e calu-chat (3000)
+		CORSOrigins: []string{"http://localhost:5173", "http://localhost:3000"},
+	}
+}
+
+// variaveis liga cada variável de ambiente ao campo que ela preenche.
+var variaveis = []struct {
+	chave   string
+	aplicar func(cfg *Config, valor string) error
+}{
+	{"PORT", func(cfg *Config, v string) error { cfg.Port = v; return nil }},
+	{"MONGO_URI", func(cfg *Config, v string) error { cfg.Mongo.URI = v; return nil }},
+	{"DB_NAME", func(cfg *Config, v string) error { cfg.Mongo.DB = v; return nil }},
+	{"JWT_SECRET", func(cfg *Config, v string) error { cfg.JWTSecret = v; return nil }},
+	{"SMTP_HOST", func(cfg *Config, v string) error { cfg.SMTP.Host = v; return nil }},
+	{"SMTP_PORT", func(cfg *Config, v string) (err error) {
+		cfg.SMTP.Port, err = strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%q não é um número", v)
+		}
+		return nil
+	}},
+	{"SMTP_EMAIL", func(cfg *Config, v string) error { cfg.SMTP.Email = v; return nil }},
+	{"SMTP_PSW", func(cfg *Config, v string) error { cfg.SMTP.Password = v; return nil }},
+	{"RATE_LIMIT_STORE", func(cfg *Config, v string) error { cfg.RateLimitStore = v; return nil }},
+	{"CEP_RESOLVER", func(cfg *Config, v string) error { cfg.CEP.Resolver = v; return nil }},
+	{"VIACEP_URL", func(cfg *Config, v string) error { cfg.CEP.ViaCEPURL = v; return nil }},
+	{"CEP_FIXTURES_FILE", func(cfg *Config, v string) error { cfg.CEP.FixturesFile = v; return nil }},
+	{"CORS_ORIGINS", func(cfg *Config, v string) error { cfg.CORSOrigins = lista(v); return nil }},
+	{"TRUSTED_PROXIES", func(cfg *Config, v string) error { cfg.TrustedProxies = lista(v); return nil }},
+}
+
+// lista separa um valor por vírgulas, descartando itens vazios.
+func lista(v string) []string {
+	var itens []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			itens = append(itens, item)
+		}
+	}
+	return itens
+}
+
+// Load lê a configuração de todas as fontes e a valida. Em caso de erro a
+// Config volta preenchida com o que foi possível ler, e o erro lista todos
+// os problemas encontrados (veja ValidationError).
+func Load() (Config, error) {
+	var problemas []string
+
+	// .env e .env.<perfil> são lidos sem alterar o ambiente do processo,
+	// que continua tendo a palavra final
+	dotenv, err := lerDotenv(".env")
+	if err != nil {
+		problemas = append(problemas, err.Error())
+	}
+	env := strings.TrimSpace(primeiroDefinido(os.Getenv("APP_ENV"), dotenv["APP_ENV"], "development"))
+	perfil, err := lerDotenv(".env." + env)
+	if err != nil {
+		problemas = append(problemas, err.Error())
+	}
+
+	cfg := padrao()
+	cfg.Env = env
+
+	arquivo, explicito := os.LookupEnv("CONFIG_FILE")
+	if !explicito {
+		arquivo = "config.yaml"
+	}
+	if err := lerYAML(&cfg, arquivo, explicito); err != nil {
+		problemas = append(problemas, err.Error())
+	}
+	if err := lerYAML(&cfg, "config."+env+".yaml", false); err != nil {
+		problemas = append(problemas, err.Error())
+	}
+
+	for _, v := range variaveis {
+		// Vazio conta como não definido, como acontece com "SMTP_PORT=" no
+		// docker-compose, e a próxima fonte é consultada
+		valor := primeiroDefinido(os.Getenv(v.chave), perfil[v.chave], dotenv[v.chave])
+		if valor == "" {
+			continue
+		}
+		if err := v.aplicar(&cfg, valor); err != nil {
+			problemas = append(problemas, v.chave+": "+err.Error())
+		}
+	}
+
+	problemas = append(problemas, cfg.validar()...)
+	if len(problemas) > 0 {
+		return cfg, &ValidationError{Env: env, Problemas: problemas}
+	}
+	return cfg, nil
+}
+
+// primeiroDefinido devolve o primeiro valor que não esteja em branco.
+func primeiroDefinido(valores ...string) string {
+	for _, v := range valores {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func lerDotenv(arquivo string) (map[string]string, error) {
+	valores, err := godotenv.Read(arquivo)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %v", arquivo, err)
+	}
+	return valores, nil
+}
+
+func lerYAML(cfg *Config, arquivo string, obrigatorio bool) error {
+	conteudo, err := os.ReadFile(arquivo)
+	if errors.Is(err, fs.ErrNotExist) && !obrigatorio {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s: %v", arquivo, err)
+	}
+
+	if err := yaml.Unmarshal(conteudo, cfg); err != nil {
+		return fmt.Errorf("%s: %v", arquivo, err)
+	}
+	return nil
+}
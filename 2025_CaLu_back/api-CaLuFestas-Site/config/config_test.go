@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// emDiretorio roda o teste em um diretório temporário com os arquivos dados,
+// longe de qualquer .env ou config.yaml do desenvolvedor.
+func emDiretorio(t *testing.T, arquivos map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for nome, conteudo := range arquivos {
+		if err := os.WriteFile(filepath.Join(dir, nome), []byte(conteudo), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	anterior, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(anterior) })
+}
+
+// limparAmbiente garante que variáveis do processo não interfiram no teste.
+func limparAmbiente(t *testing.T) {
+	t.Helper()
+	for _, chave := range []string{"APP_ENV", "CONFIG_FILE"} {
+		t.Setenv(chave, "")
+		os.Unsetenv(chave)
+	}
+	for _, v := range variaveis {
+		t.Setenv(v.chave, "")
+		os.Unsetenv(v.chave)
+	}
+}
+
+// minimo define o necessário para a validação passar fora de produção.
+func minimo(t *testing.T) {
+	t.Helper()
+	t.Setenv("MONGO_URI", "mongodb://localhost:27017")
+	t.Setenv("DB_NAME", "calufestas")
+	t.Setenv("JWT_SECRET", "segredo")
+}
+
+func problemas(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		t.Fatal("esperado erro de validação")
+	}
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("erro %T, esperado *ValidationError", err)
+	}
+	return strings.Join(verr.Problemas, "\n")
+}
+
+func TestTrustedProxies(t *testing.T) {
+	emDiretorio(t, nil)
+	limparAmbiente(t)
+	minimo(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.TrustedProxies) != 0 {
+		t.Fatalf("por padrão nenhum proxy deve ser confiável: %v", cfg.TrustedProxies)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1, 172.16.0.0/12")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.TrustedProxies, []string{"10.0.0.1", "172.16.0.0/12"}) {
+		t.Fatalf("TrustedProxies = %v", cfg.TrustedProxies)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "meu-proxy")
+	_, err = Load()
+	if p := problemas(t, err); !strings.Contains(p, "TRUSTED_PROXIES") {
+		t.Fatalf("problemas sem TRUSTED_PROXIES: %s", p)
+	}
+}
+
+func TestValorVazioUsaProximaFonte(t *testing.T) {
+	emDiretorio(t, map[string]string{
+		".env":             "MONGO_URI=mongodb://dotenv:27017\nDB_NAME=calufestas\nJWT_SECRET=segredo\nPORT=9090\n",
+		".env.development": "DB_NAME=calufestas_dev\n",
+	})
+	limparAmbiente(t)
+	// Como o docker-compose faz com "DB_NAME=" e "PORT=${PORT}" sem valor
+	t.Setenv("MONGO_URI", "")
+	t.Setenv("DB_NAME", " ")
+	t.Setenv("PORT", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Mongo.URI != "mongodb://dotenv:27017" || cfg.Mongo.DB != "calufestas_dev" || cfg.Port != "9090" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+}
+
+func TestCORSOrigins(t *testing.T) {
+	emDiretorio(t, nil)
+	limparAmbiente(t)
+	minimo(t)
+
+	t.Setenv("CORS_ORIGINS", "https://calufestas.com.br, http://localhost:5173")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.CORSOrigins, []string{"https://calufestas.com.br", "http://localhost:5173"}) {
+		t.Fatalf("CORSOrigins = %v", cfg.CORSOrigins)
+	}
+
+	for _, origem := range []string{"calufestas.com.br", "localhost:5173", "ftp://calufestas.com.br", "https://calufestas.com.br/"} {
+		t.Setenv("CORS_ORIGINS", origem)
+		_, err := Load()
+		if p := problemas(t, err); !strings.Contains(p, "CORS_ORIGINS") {
+			t.Errorf("%q aceita: %s", origem, p)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ValidationError reúne todos os problemas da configuração, para que sejam
+// corrigidos de uma vez em vez de um a cada tentativa de subida.
+type ValidationError struct {
+	Env       string
+	Problemas []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "configuração inválida (perfil %s):", e.Env)
+	for _, p := range e.Problemas {
+		b.WriteString("\n  - ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// Em produção o segredo precisa ter ao menos 256 bits, o tamanho da chave
+// do HS256
+const tamanhoMinimoSegredo = 32
+
+func (cfg Config) validar() []string {
+	var problemas []string
+	obrigatorio := func(chave, valor string) {
+		if strings.TrimSpace(valor) == "" {
+			problemas = append(problemas, chave+": obrigatório")
+		}
+	}
+
+	obrigatorio("MONGO_URI", cfg.Mongo.URI)
+	obrigatorio("DB_NAME", cfg.Mongo.DB)
+
+	// Com o segredo vazio os tokens seriam assinados com uma chave vazia
+	obrigatorio("JWT_SECRET", cfg.JWTSecret)
+	if cfg.Env == Production && cfg.JWTSecret != "" && len(cfg.JWTSecret) < tamanhoMinimoSegredo {
+		problemas = append(problemas, fmt.Sprintf("JWT_SECRET: precisa ter ao menos %d caracteres em produção", tamanhoMinimoSegredo))
+	}
+
+	if porta, err := strconv.Atoi(cfg.Port); err != nil || porta <= 0 || porta > 65535 {
+		problemas = append(problemas, fmt.Sprintf("PORT: %q não é uma porta válida", cfg.Port))
+	}
+
+	// Fora de produção a API sobe sem SMTP; o /readyz aponta o que falta
+	if cfg.Env == Production {
+		obrigatorio("SMTP_HOST", cfg.SMTP.Host)
+		obrigatorio("SMTP_EMAIL", cfg.SMTP.Email)
+		obrigatorio("SMTP_PSW", cfg.SMTP.Password)
+		if cfg.SMTP.Port == 0 {
+			problemas = append(problemas, "SMTP_PORT: obrigatório")
+		}
+	}
+	if cfg.SMTP.Port < 0 || cfg.SMTP.Port > 65535 {
+		problemas = append(problemas, fmt.Sprintf("SMTP_PORT: %d não é uma porta válida", cfg.SMTP.Port))
+	}
+
+	if cfg.RateLimitStore != "memory" && cfg.RateLimitStore != "mongo" {
+		problemas = append(problemas, fmt.Sprintf("RATE_LIMIT_STORE: %q inválido, use memory ou mongo", cfg.RateLimitStore))
+	}
+	if cfg.CEP.Resolver != "viacep" && cfg.CEP.Resolver != "offline" {
+		problemas = append(problemas, fmt.Sprintf("CEP_RESOLVER: %q inválido, use viacep ou offline", cfg.CEP.Resolver))
+	}
+	if len(cfg.CORSOrigins) == 0 {
+		problemas = append(problemas, "CORS_ORIGINS: informe ao menos uma origem")
+	}
+	for _, origem := range cfg.CORSOrigins {
+		if !origemValida(origem) {
+			problemas = append(problemas, fmt.Sprintf("CORS_ORIGINS: %q inválida, use \"*\" ou esquema e host, como https://calufestas.com.br", origem))
+		}
+	}
+	for _, proxy := range cfg.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problemas = append(problemas, fmt.Sprintf("TRUSTED_PROXIES: %q não é um IP nem um CIDR", proxy))
+			}
+		}
+	}
+
+	return problemas
+}
+
+// origemValida aceita "*" ou uma origem como o navegador a envia: esquema
+// http ou https e host, sem caminho. O middleware de CORS entra em pânico
+// com origens sem esquema, e uma barra no fim nunca casaria com o cabeçalho.
+func origemValida(origem string) bool {
+	if origem == "*" {
+		return true
+	}
+	u, err := url.Parse(origem)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
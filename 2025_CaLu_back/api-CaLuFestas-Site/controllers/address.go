@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/cep"
+	"github.com/gin-gonic/gin"
+)
+
+// LookupCEP devolve o endereço de um CEP para o formulário de entrega.
+func (h *Handler) LookupCEP(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+	defer cancel()
+
+	endereco, err := h.addresses.Resolve(ctx, c.Param("cep"))
+	if errors.Is(err, models.ErrCEPInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_CEP"})
+		return
+	} else if errors.Is(err, cep.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": "CEP_NOT_FOUND"})
+		return
+	} else if err != nil {
+		log.Printf("falha ao consultar CEP %s: %v", c.Param("cep"), err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Serviço de CEP indisponível"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endereco)
+}
+
+// prepararEndereco confere o endereço estruturado da locação, quando houver,
+// e mantém o campo texto preenchido para quem ainda lê só ele.
+func (h *Handler) prepararEndereco(c *gin.Context, locacao *models.Locacao) bool {
+	if locacao.EnderecoEntrega == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+	defer cancel()
+
+	campos := map[string]string{}
+	if err := h.conferirEndereco(ctx, locacao.EnderecoEntrega, "endereco_entrega.", campos); err != nil {
+		responderErroCEP(c, err, "endereco_entrega.cep")
+		return false
+	}
+	if len(campos) > 0 {
+		responderCamposInvalidos(c, campos)
+		return false
+	}
+
+	locacao.Endereco = locacao.EnderecoEntrega.String()
+	return true
+}
+
+// conferirEndereco normaliza o endereço e, com um CEP bem formado, troca
+// logradouro, bairro, cidade e UF pelos do AddressResolver, já que são eles
+// que definem a zona de entrega. CEPs gerais de cidades pequenas vêm sem
+// logradouro e bairro; só nesse caso vale o que o cliente digitou. Campos
+// inválidos vão para campos, com o prefixo dado; o erro é o da consulta.
+func (h *Handler) conferirEndereco(ctx context.Context, endereco *models.Endereco, prefixo string, campos map[string]string) error {
+	endereco.Normalizar()
+
+	if _, err := models.NormalizarCEP(endereco.CEP); err == nil {
+		resolvido, err := h.addresses.Resolve(ctx, endereco.CEP)
+		if err != nil {
+			return err
+		}
+		if resolvido.Logradouro != "" {
+			endereco.Logradouro = resolvido.Logradouro
+		}
+		if resolvido.Bairro != "" {
+			endereco.Bairro = resolvido.Bairro
+		}
+		endereco.Cidade = resolvido.Cidade
+		endereco.UF = resolvido.UF
+		endereco.Normalizar()
+	}
+
+	for campo, motivo := range endereco.Validar() {
+		campos[prefixo+campo] = motivo
+	}
+	return nil
+}
+
+// responderErroCEP responde a falha da consulta do CEP informado em campo.
+func responderErroCEP(c *gin.Context, err error, campo string) {
+	e := erroCEP(err, campo)
+	c.JSON(e.status, e.body)
+}
+
+// erroCEP traduz a falha da consulta do CEP informado em campo: CEP inválido
+// ou inexistente é erro do cliente; qualquer outra falha é do serviço de CEP.
+func erroCEP(err error, campo string) *erroHTTP {
+	if errors.Is(err, models.ErrCEPInvalido) {
+		return &erroHTTP{http.StatusUnprocessableEntity, gin.H{
+			"error":  "Dados inválidos",
+			"code":   "VALIDATION_ERROR",
+			"fields": gin.H{campo: err.Error()},
+		}}
+	}
+	if errors.Is(err, cep.ErrNotFound) {
+		return &erroHTTP{http.StatusUnprocessableEntity, gin.H{
+			"error":  err.Error(),
+			"code":   "CEP_NOT_FOUND",
+			"fields": gin.H{campo: err.Error()},
+		}}
+	}
+	log.Printf("falha ao consultar CEP: %v", err)
+	return &erroHTTP{http.StatusBadGateway, gin.H{"error": "Serviço de CEP indisponível"}}
+}
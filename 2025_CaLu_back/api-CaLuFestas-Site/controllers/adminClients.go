@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var erroContaDesativada = gin.H{"error": "Conta desativada", "code": "ACCOUNT_DISABLED"}
+
+// GetClients lista os clientes paginados. ?q= busca por parte do nome ou do
+// e-mail; ?cargo= e ?status= filtram.
+func (h *Handler) GetClients(c *gin.Context) {
+	paginacao := lerPaginacao(c)
+
+	filtro := repository.ClientFilter{
+		Busca:  strings.TrimSpace(c.Query("q")),
+		Cargo:  c.Query("cargo"),
+		Status: c.Query("status"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	clients, total, err := h.clients.List(ctx, filtro, paginacao.Intervalo())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	paginacao.Total = total
+
+	views := make([]models.ClientView, len(clients))
+	for i, client := range clients {
+		views[i] = client.View()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": views, "paginacao": paginacao})
+}
+
+// GetClient mostra um cliente com o histórico de locações, paginado e das
+// mais recentes para as mais antigas.
+func (h *Handler) GetClient(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, ok := h.clientePorParametro(ctx, c)
+	if !ok {
+		return
+	}
+
+	paginacao := lerPaginacao(c)
+
+	locacoes, total, err := h.locations.ListByEmail(ctx, client.Email, paginacao.Intervalo())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch locations"})
+		return
+	}
+	paginacao.Total = total
+
+	c.JSON(http.StatusOK, gin.H{
+		"cliente":  client.View(),
+		"locacoes": gin.H{"items": viewsLocacoes(locacoes), "paginacao": paginacao},
+	})
+}
+
+// UpdateClientCargo promove ou rebaixa um cliente. Um administrador não pode
+// rebaixar a si mesmo, o que também impede que o sistema fique sem nenhum.
+func (h *Handler) UpdateClientCargo(c *gin.Context) {
+	var request models.CargoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, ok := h.clientePorParametro(ctx, c)
+	if !ok {
+		return
+	}
+
+	if client.Email == c.GetString("user") && request.Cargo != models.CargoAdmin {
+		c.JSON(http.StatusConflict, gin.H{"error": "Não é possível rebaixar a própria conta", "code": "SELF_DEMOTION"})
+		return
+	}
+
+	// O AuthMiddleware lê o cargo do banco, então a mudança vale já na
+	// próxima requisição sem precisar revogar sessões
+	client, err := h.clients.Update(ctx, client.ID, repository.ClientChanges{Cargo: &request.Cargo})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, client.View())
+}
+
+// DeactivateClient bloqueia a conta e encerra as sessões abertas.
+func (h *Handler) DeactivateClient(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, ok := h.clientePorParametro(ctx, c)
+	if !ok {
+		return
+	}
+
+	if client.Email == c.GetString("user") {
+		c.JSON(http.StatusConflict, gin.H{"error": "Não é possível desativar a própria conta", "code": "SELF_DEACTIVATION"})
+		return
+	}
+	if client.Desativado() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Conta já está desativada"})
+		return
+	}
+
+	status := models.StatusDesativado
+	client, err := h.clients.Update(ctx, client.ID, repository.ClientChanges{Status: &status})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Access tokens já emitidos são barrados pelo AuthMiddleware; os refresh
+	// tokens são revogados para que nada fique pendente se a conta voltar
+	if err := h.revogarRefreshTokens(ctx, client.Email); err != nil {
+		log.Printf("falha ao revogar sessões de %s: %v", client.Email, err)
+	}
+
+	c.JSON(http.StatusOK, client.View())
+}
+
+// ActivateClient reativa uma conta desativada. Contas pendentes continuam
+// dependendo da verificação de e-mail.
+func (h *Handler) ActivateClient(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, ok := h.clientePorParametro(ctx, c)
+	if !ok {
+		return
+	}
+
+	reativada, err := h.clients.SetStatusIf(ctx, client.ID, models.StatusDesativado, models.StatusAtivo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !reativada {
+		c.JSON(http.StatusConflict, gin.H{"error": "Conta não está desativada"})
+		return
+	}
+
+	client.Status = models.StatusAtivo
+	c.JSON(http.StatusOK, client.View())
+}
+
+func (h *Handler) clientePorParametro(ctx context.Context, c *gin.Context) (models.Client, bool) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return models.Client{}, false
+	}
+
+	client, err := h.clients.FindByID(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente não encontrado"})
+		return client, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return client, false
+	}
+	return client, true
+}
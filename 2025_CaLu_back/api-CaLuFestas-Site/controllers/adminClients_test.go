@@ -0,0 +1,121 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+)
+
+func (a *ambiente) idDe(email string) string {
+	a.t.Helper()
+	client, err := a.clients.FindByEmail(context.Background(), email)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return client.ID.Hex()
+}
+
+func nomes(t *testing.T, pagina M) string {
+	t.Helper()
+	itens, _ := pagina["items"].([]any)
+	var lista []string
+	for _, item := range itens {
+		lista = append(lista, item.(M)["nome"].(string))
+	}
+	return strings.Join(lista, ",")
+}
+
+func TestListarClientes(t *testing.T) {
+	a := novoAmbiente(t)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	for _, nome := range []string{"Ana", "Bia", "Caio", "Davi"} {
+		a.cadastrar(nome, strings.ToLower(nome)+"@festas.com", "senha1234")
+	}
+	a.esperar(a.fazer("GET", "/api/privateClients/", a.login("ana@festas.com", "senha1234"), nil), http.StatusForbidden)
+
+	pagina := a.esperar(a.fazer("GET", "/api/privateClients/?limit=2&page=2", admin, nil), http.StatusOK).JSON(t)
+	paginacao := pagina["paginacao"].(M)
+	if got := nomes(t, pagina); got != "Bia,Caio" || paginacao["total"] != 5.0 || paginacao["page"] != 2.0 || paginacao["limit"] != 2.0 {
+		t.Fatalf("página 2 = %s, paginação %v", got, paginacao)
+	}
+	pagina = a.esperar(a.fazer("GET", "/api/privateClients/?limit=2&page=4", admin, nil), http.StatusOK).JSON(t)
+	if got := nomes(t, pagina); got != "" {
+		t.Fatalf("página além do fim = %s", got)
+	}
+
+	// A busca olha o nome e o e-mail, sem diferenciar maiúsculas
+	pagina = a.esperar(a.fazer("GET", "/api/privateClients/?q=FESTAS", admin, nil), http.StatusOK).JSON(t)
+	if got := nomes(t, pagina); got != "Ana,Bia,Caio,Davi" || pagina["paginacao"].(M)["total"] != 4.0 {
+		t.Fatalf("busca por e-mail = %s", got)
+	}
+	pagina = a.esperar(a.fazer("GET", "/api/privateClients/?q=ai", admin, nil), http.StatusOK).JSON(t)
+	if got := nomes(t, pagina); got != "Caio" {
+		t.Fatalf("busca por nome = %s", got)
+	}
+	pagina = a.esperar(a.fazer("GET", "/api/privateClients/?cargo=admin", admin, nil), http.StatusOK).JSON(t)
+	if got := nomes(t, pagina); got != "Admin" {
+		t.Fatalf("filtro por cargo = %s", got)
+	}
+}
+
+func TestAdminNaoRebaixaASiMesmo(t *testing.T) {
+	a := novoAmbiente(t)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	caminho := "/api/privateClients/" + a.idDe("admin@calu.com") + "/cargo"
+
+	corpo := a.esperar(a.fazer("PATCH", caminho, admin, M{"cargo": models.CargoUser}), http.StatusConflict).JSON(t)
+	if corpo["code"] != "SELF_DEMOTION" {
+		t.Fatalf("resposta = %v", corpo)
+	}
+	a.esperar(a.fazer("GET", "/api/privateClients/", admin, nil), http.StatusOK)
+
+	// Promover outra conta funciona e vale já na próxima requisição
+	ana := "/api/privateClients/" + a.idDe("ana@calu.com") + "/cargo"
+	a.esperar(a.fazer("PATCH", ana, admin, M{"cargo": "dono"}), http.StatusUnprocessableEntity)
+	if got := a.esperar(a.fazer("PATCH", ana, admin, M{"cargo": models.CargoAdmin}), http.StatusOK).JSON(t); got["cargo"] != models.CargoAdmin {
+		t.Fatalf("resposta = %v", got)
+	}
+	a.esperar(a.fazer("GET", "/api/privateClients/", a.login("ana@calu.com", "senha1234"), nil), http.StatusOK)
+}
+
+func TestDesativarEReativarConta(t *testing.T) {
+	a := novoAmbiente(t)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	sessao := a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusOK).JSON(t)
+	ana := sessao["token"].(string)
+	caminho := "/api/privateClients/" + a.idDe("ana@calu.com")
+
+	a.esperar(a.fazer("POST", "/api/privateClients/"+a.idDe("admin@calu.com")+"/deactivate", admin, nil), http.StatusConflict)
+	a.esperar(a.fazer("POST", caminho+"/activate", admin, nil), http.StatusConflict)
+
+	if got := a.esperar(a.fazer("POST", caminho+"/deactivate", admin, nil), http.StatusOK).JSON(t); got["status"] != models.StatusDesativado {
+		t.Fatalf("resposta = %v", got)
+	}
+	a.esperar(a.fazer("POST", caminho+"/deactivate", admin, nil), http.StatusConflict)
+
+	// O token já emitido, o login e o refresh param de valer
+	for _, r := range []resposta{
+		a.fazer("GET", "/api/me", ana, nil),
+		a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}),
+	} {
+		if corpo := a.esperar(r, http.StatusForbidden).JSON(t); corpo["code"] != "ACCOUNT_DISABLED" {
+			t.Fatalf("resposta = %v", corpo)
+		}
+	}
+	if r := a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": sessao["refresh_token"]}); r.Code == http.StatusOK {
+		t.Fatalf("refresh de conta desativada: %s", r.Body)
+	}
+
+	if got := a.esperar(a.fazer("POST", caminho+"/activate", admin, nil), http.StatusOK).JSON(t); got["status"] != models.StatusAtivo {
+		t.Fatalf("resposta = %v", got)
+	}
+	nova := a.login("ana@calu.com", "senha1234")
+	a.esperar(a.fazer("GET", "/api/me", nova, nil), http.StatusOK)
+	// As sessões de antes da desativação continuam encerradas
+	a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": sessao["refresh_token"]}), http.StatusUnauthorized)
+}
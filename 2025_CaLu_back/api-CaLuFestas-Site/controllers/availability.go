@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (h *Handler) ProductAvailability(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	inicio, fim, err := models.ParsePeriodo(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	produto, err := h.products.FindByID(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	disponibilidade, err := h.disponibilidadeProduto(ctx, produto, inicio, fim, bson.NilObjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular disponibilidade"})
+		return
+	}
+
+	c.JSON(http.StatusOK, disponibilidade)
+}
+
+// disponibilidadeProduto calcula quantas unidades do produto estão livres em
+// [inicio, fim), desconsiderando a locação ignorar (útil ao reavaliar uma
+// locação que já existe).
+func (h *Handler) disponibilidadeProduto(ctx context.Context, produto models.Product, inicio, fim time.Time, ignorar bson.ObjectID) (models.Disponibilidade, error) {
+	locations, err := h.locations.Reserving(ctx, produto.ID, inicio, fim, ignorar)
+	if err != nil {
+		return models.Disponibilidade{}, err
+	}
+
+	var reservas []models.Reserva
+	for _, locacao := range locations {
+		de, ate, err := locacao.Periodo()
+		if err != nil {
+			// Sem período conhecido não há como saber se conflita
+			continue
+		}
+		for _, item := range locacao.Items {
+			if item.ProdutoID == produto.ID {
+				reservas = append(reservas, models.Reserva{Inicio: de, Fim: ate, Quantidade: item.Quantidade})
+			}
+		}
+	}
+
+	return models.CalcularDisponibilidade(produto, inicio, fim, reservas), nil
+}
+
+// verificarDisponibilidade devolve os produtos da locação cuja quantidade
+// pedida excede o que está livre no período. Uma lista vazia significa que a
+// locação cabe no estoque.
+func (h *Handler) verificarDisponibilidade(ctx context.Context, locacao models.Locacao, inicio, fim time.Time) ([]models.Disponibilidade, error) {
+	solicitado := map[bson.ObjectID]int{}
+	nomes := map[bson.ObjectID]string{}
+	var ids []bson.ObjectID
+	for _, item := range locacao.Items {
+		if _, ok := solicitado[item.ProdutoID]; !ok {
+			ids = append(ids, item.ProdutoID)
+			nomes[item.ProdutoID] = item.Nome
+		}
+		solicitado[item.ProdutoID] += item.Quantidade
+	}
+
+	var indisponiveis []models.Disponibilidade
+	for _, id := range ids {
+		produto, err := h.products.FindByID(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			indisponiveis = append(indisponiveis, models.Disponibilidade{ProdutoID: id, Nome: nomes[id], Inicio: inicio, Fim: fim, Solicitada: solicitado[id]})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		disponibilidade, err := h.disponibilidadeProduto(ctx, produto, inicio, fim, locacao.ID)
+		if err != nil {
+			return nil, err
+		}
+		if solicitado[id] > disponibilidade.Disponivel {
+			disponibilidade.Solicitada = solicitado[id]
+			indisponiveis = append(indisponiveis, disponibilidade)
+		}
+	}
+
+	return indisponiveis, nil
+}
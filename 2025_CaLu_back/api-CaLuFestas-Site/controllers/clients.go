@@ -2,90 +2,103 @@ package controllers
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
 	"github.com/Psnsilvino/CaluFestas-Site-api/models"
-	"github.com/Psnsilvino/CaluFestas-Site-api/utils/email"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
 	"github.com/Psnsilvino/CaluFestas-Site-api/utils/otputil"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/tokenutil"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-var emailSender email.EmailSender
+const (
+	validadeCodigoReset = 15 * time.Minute
+	validadeResetToken  = 15 * time.Minute
+)
 
-func InitEmailSender(sender email.EmailSender) {
-	emailSender = sender
-}
+func (h *Handler) Register(c *gin.Context) {
+	var request models.RegisterRequest
+	if err := lerCorpo(c, &request); err != nil {
+		responderBind(c, err)
+		return
+	}
 
-func Register(c *gin.Context) {
-	var client models.Client
-	if err := c.ShouldBindJSON(&client); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := models.ValidarSenha(request.Senha); err != nil {
+		responderCamposInvalidos(c, map[string]string{"senha": err.Error()})
 		return
 	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(client.Senha), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Senha), bcrypt.DefaultCost)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
-	client.Senha = string(hashedPassword)
-	client.ID = primitive.NewObjectID()
-	client.Cargo = "user"
 
-	_, err = database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").InsertOne(context.Background(), client)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-		return
+	client := models.Client{
+		ID:     bson.NewObjectID(),
+		Nome:   request.Nome,
+		Email:  request.Email,
+		Senha:  string(hashedPassword),
+		Cargo:  models.CargoUser,
+		Status: models.StatusPendente,
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
-}
-
-func GetClients(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").Find(ctx, bson.M{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+	// O índice único em clients.email é o que garante a unicidade mesmo com
+	// cadastros simultâneos
+	err = h.clients.Insert(ctx, client)
+	if errors.Is(err, repository.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "E-mail já cadastrado",
+			"code":   "EMAIL_TAKEN",
+			"fields": gin.H{"email": "já cadastrado"},
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var clients []models.Client
-	if err := cursor.All(ctx, &clients); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing users"})
-		return
+	// A conta já existe; se o e-mail falhar o cliente pode pedir o reenvio
+	if err := h.enviarCodigoVerificacao(ctx, client.Email); err != nil {
+		log.Printf("falha ao enviar verificação para %s: %v", client.Email, err)
 	}
 
-	c.JSON(http.StatusOK, clients)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":               "User registered successfully",
+		"verification_required": true,
+	})
 }
 
+func (h *Handler) Login(c *gin.Context) {
+	var loginData models.LoginRequest
+	if err := lerCorpo(c, &loginData); err != nil {
+		responderBind(c, err)
+		return
+	}
 
-func Login(c *gin.Context) {
-	var loginData models.Client
-	if err := c.ShouldBindJSON(&loginData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !limitar(c, h.loginPorEmail, loginData.Email) {
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	var client models.Client
-	err := database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").FindOne(ctx, bson.M{"email": loginData.Email}).Decode(&client)
-	if err == mongo.ErrNoDocuments {
+	client, err := h.clients.FindByEmail(ctx, loginData.Email)
+	if errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	} else if err != nil {
@@ -100,25 +113,29 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"nome": client.Nome,
-		"email": client.Email,
-		"cargo": client.Cargo,
-		"expire": time.Now().UTC().Add(48 * time.Hour),
-	})
+	if !client.EmailVerificado() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "E-mail não verificado", "code": "EMAIL_NOT_VERIFIED"})
+		return
+	}
+	if client.Desativado() {
+		c.JSON(http.StatusForbidden, erroContaDesativada)
+		return
+	}
 
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	if err := h.loginPorEmail.Reset(ctx, loginData.Email); err != nil {
+		log.Printf("falha ao zerar tentativas de login: %v", err)
+	}
+
+	tokens, err := h.emitirTokens(ctx, client, "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
-	})
+	c.JSON(http.StatusOK, tokens)
 }
 
-func ForgotPassword(c *gin.Context)  {
+func (h *Handler) ForgotPassword(c *gin.Context)  {
 
 	var email string
 	var emailModel models.EmailReset
@@ -127,15 +144,18 @@ func ForgotPassword(c *gin.Context)  {
 		return
 	}
 
-	email = emailModel.Email
+	email = models.NormalizeEmail(emailModel.Email)
+
+	if !limitar(c, h.resetPorEmail, email) {
+		return
+	}
 
 	// verificar se o cliente existe
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	var client models.Client
-	err := database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").FindOne(ctx, bson.M{"email": email}).Decode(&client)
-	if err == mongo.ErrNoDocuments {
+	client, err := h.clients.FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	} else if err != nil {
@@ -143,6 +163,16 @@ func ForgotPassword(c *gin.Context)  {
 		return
 	}
 
+	// Pedir um código novo não pode servir para escapar do bloqueio por erros
+	bloqueada, err := h.resets.FindLocked(ctx, email, time.Now().UTC())
+	if err == nil {
+		middle.TooManyRequests(c, time.Until(*bloqueada.LockedUntil))
+		return
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
 	// Generate reset token (OTP)
 	resetToken, err := otputil.GenerateOTP(6)
 	if err != nil {
@@ -150,30 +180,35 @@ func ForgotPassword(c *gin.Context)  {
 		return 
 	}
 
-	expiresAt := time.Now().UTC().Add(15 * time.Minute)
-	passwordResetEntry := &models.PasswordResetEntry{
+	now := time.Now().UTC()
+	passwordResetEntry := models.PasswordResetEntry{
+		ID:        bson.NewObjectID(),
 		Email:     client.Email,
 		OTPCode:   resetToken,
-		ExpiresAt: expiresAt,
+		ExpiresAt: now.Add(validadeCodigoReset),
+		CreatedAt: now,
 	}
 
-	err = CreatePasswordResetEntry(c, passwordResetEntry)
+	// Só o código mais recente de cada e-mail é aceito
+	err = h.resets.Replace(ctx, passwordResetEntry)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating entry"})
 		return 
 	}
 
-	err = emailSender.SendPasswordResetToken(client.Email, resetToken)
+	err = h.emailSender.SendPasswordResetToken(client.Email, resetToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error sending email"})
 		return 
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
+	c.JSON(http.StatusCreated, gin.H{"message": "Codigo enviado"})
 
 }
 
-func VerifyCode(c *gin.Context)  {
+// VerifyCode troca o OTP enviado por e-mail por um reset token de uso único,
+// que é o que autoriza UpdatePassword.
+func (h *Handler) VerifyCode(c *gin.Context)  {
 
 	var email, otpcode string
 	var emailResetModel models.EmailReset
@@ -182,30 +217,23 @@ func VerifyCode(c *gin.Context)  {
 		return
 	}
 
-	email = emailResetModel.Email
+	email = models.NormalizeEmail(emailResetModel.Email)
 	otpcode = emailResetModel.OTPCode
 
-	// verificar se o cliente existe
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	var client models.Client
-	err := database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").FindOne(ctx, bson.M{"email": email}).Decode(&client)
-	if err == mongo.ErrNoDocuments {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "o"})
+	entry, err := h.resets.FindPending(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sem requisicao"})
 		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	var entry models.PasswordResetEntry
-	err = database.DB.Database(os.Getenv("DB_NAME")).Collection("senhasEsquecidas").FindOne(ctx, bson.M{"email": email, "isverified": false}).Decode(&entry)
-	if err == mongo.ErrNoDocuments {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "ostra"})
-		return
-	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	if entry.LockedUntil != nil && time.Now().UTC().Before(*entry.LockedUntil) {
+		middle.TooManyRequests(c, time.Until(*entry.LockedUntil))
 		return
 	}
 
@@ -216,41 +244,100 @@ func VerifyCode(c *gin.Context)  {
 	}
 
 	// Verify OTP
-	if entry.OTPCode != otpcode {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OTP"})
+	if subtle.ConstantTimeCompare([]byte(entry.OTPCode), []byte(otpcode)) != 1 {
+		h.registrarFalhaOTP(ctx, c, entry)
+		return
+	}
+
+	resetToken, hash, err := tokenutil.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
 		return
 	}
 
-	result, _ := database.DB.Database(os.Getenv("DB_NAME")).Collection("senhasEsquecidas").UpdateOne(ctx, bson.M{"email": email, "isverified": false}, bson.M{"$set": bson.M{"isverified": true, "expiresat": time.Now().UTC()}})
+	// MarkVerified só vale uma vez, o que garante que o mesmo OTP só gere um reset token
+	verificado, err := h.resets.MarkVerified(ctx, entry.ID, hash, time.Now().UTC().Add(validadeResetToken))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !verificado {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sem requisicao"})
+		return
+	}
 
-	if result.MatchedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Sem requisicao"})
-        return
-    }
+	c.JSON(http.StatusOK, gin.H{"message": "Codigo correto", "reset_token": resetToken})
 
-	c.JSON(http.StatusOK, gin.H{"message": "Codigo correto"})
+}
 
+// registrarFalhaOTP conta um código errado. Ao atingir maxTentativasOTP o
+// pedido fica bloqueado e nem um código novo pode ser solicitado até o fim do
+// bloqueio.
+func (h *Handler) registrarFalhaOTP(ctx context.Context, c *gin.Context, entry models.PasswordResetEntry) {
+	atualizada, err := h.resets.IncrementAttempts(ctx, entry.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if atualizada.Attempts >= maxTentativasOTP {
+		bloqueio := time.Now().UTC().Add(bloqueioOTP)
+		if err := h.resets.Lock(ctx, entry.ID, bloqueio); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		middle.TooManyRequests(c, bloqueioOTP)
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":                "Invalid OTP",
+		"tentativas_restantes": maxTentativasOTP - atualizada.Attempts,
+	})
 }
 
-func UpdatePassword(c *gin.Context)  {
+func (h *Handler) UpdatePassword(c *gin.Context)  {
 
 	var email, newPassword string;
 	var passwordResetModel models.PasswordReset
-	if err := c.ShouldBindJSON(&passwordResetModel); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := lerCorpo(c, &passwordResetModel); err != nil {
+		responderBind(c, err)
 		return
 	}
 
-	email = passwordResetModel.Email 
-	newPassword = passwordResetModel.Password 
+	email = passwordResetModel.Email
+	newPassword = passwordResetModel.Password
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := models.ValidarSenha(newPassword); err != nil {
+		responderCamposInvalidos(c, map[string]string{"password": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var client models.Client
-	err := database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").FindOne(ctx, bson.M{"email": email}).Decode(&client)
-	if err == mongo.ErrNoDocuments {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "o"})
+	// O token só vale para o e-mail que pediu a redefinição
+	entry, err := h.resets.FindByResetToken(ctx, email, tokenutil.HashToken(passwordResetModel.ResetToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid reset token"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if entry.UsedAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid reset token"})
+		return
+	}
+	if time.Now().UTC().After(entry.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Reset token expired"})
+		return
+	}
+
+	client, err := h.clients.FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid reset token"})
 		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
@@ -270,20 +357,38 @@ func UpdatePassword(c *gin.Context)  {
 		return
 	}
 
-	client.Senha = string(hashedPassword)
-
-	result, _ := database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").UpdateOne(ctx, bson.M{"email": email}, bson.M{"$set": bson.M{"senha": client.Senha}})
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		// Consome o token; uma segunda requisição com o mesmo token não é aceita
+		usado, err := h.resets.MarkUsed(tx.Context(), entry.ID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if !usado {
+			return &erroHTTP{http.StatusUnauthorized, gin.H{"error": "Invalid reset token"}}
+		}
+		tx.OnRollback(func(ctx context.Context) error {
+			return h.resets.UnmarkUsed(ctx, entry.ID)
+		})
+
+		senha := string(hashedPassword)
+		_, err = h.clients.Update(tx.Context(), client.ID, repository.ClientChanges{Senha: &senha})
+		return err
+	})
+	if err != nil {
+		responderErro(c, err, "Database error")
+		return
+	}
 
-	if result.MatchedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Sem requisicao"})
-        return
-    }
+	// Quem tinha a senha antiga não deve continuar logado
+	if err := h.revogarRefreshTokens(ctx, email); err != nil {
+		log.Printf("falha ao revogar sessões de %s: %v", email, err)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Senha atualizada com sucesso"})
 
 }
 
-func Me(c *gin.Context) {
+func (h *Handler) Me(c *gin.Context) {
 	email := c.GetString("user")
     cargo := c.GetString("cargo")
 
@@ -0,0 +1,88 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCadastroELogin(t *testing.T) {
+	a := novoAmbiente(t)
+
+	a.esperar(a.fazer("POST", "/api/clients/", "", M{"nome": "Ana", "email": "ana@calu.com", "senha": "senha1234"}), http.StatusCreated)
+	a.esperar(a.fazer("POST", "/api/clients/", "", M{"nome": "Ana", "email": "ana@calu.com", "senha": "senha1234"}), http.StatusConflict)
+
+	// Sem confirmar o e-mail o login é recusado
+	a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusForbidden)
+
+	a.esperar(a.fazer("POST", "/api/clients/verifyEmail", "", M{"email": "ana@calu.com", "otp_code": "000000"}), http.StatusBadRequest)
+	a.esperar(a.fazer("POST", "/api/clients/verifyEmail", "", M{"email": "ana@calu.com", "otp_code": a.email.ultimo("ana@calu.com")}), http.StatusOK)
+
+	a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "errada123"}), http.StatusUnauthorized)
+	token := a.login("ana@calu.com", "senha1234")
+
+	perfil := a.esperar(a.fazer("GET", "/api/me", token, nil), http.StatusOK).JSON(t)
+	if perfil["email"] != "ana@calu.com" {
+		t.Fatalf("perfil de outra conta: %v", perfil)
+	}
+}
+
+func TestEmailAparadoAntesDaValidacao(t *testing.T) {
+	a := novoAmbiente(t)
+
+	a.esperar(a.fazer("POST", "/api/clients/", "", M{"nome": "  Ana  ", "email": "  Ana@Calu.com ", "senha": "senha1234"}), http.StatusCreated)
+	a.esperar(a.fazer("POST", "/api/clients/", "", M{"nome": "Ana", "email": "ana@calu.com", "senha": "senha1234"}), http.StatusConflict)
+	a.esperar(a.fazer("POST", "/api/clients/", "", M{"nome": "   ", "email": "bia@calu.com", "senha": "senha1234"}), http.StatusUnprocessableEntity)
+
+	a.esperar(a.fazer("POST", "/api/clients/verifyEmail", "", M{"email": "ana@calu.com", "otp_code": a.email.ultimo("ana@calu.com")}), http.StatusOK)
+	a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": " ANA@calu.com", "senha": "senha1234"}), http.StatusOK)
+}
+
+func TestLogoutRevogaTokens(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	sessao := a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusOK).JSON(t)
+	token := sessao["token"].(string)
+
+	a.esperar(a.fazer("POST", "/api/clients/logout", token, M{"refresh_token": sessao["refresh_token"]}), http.StatusOK)
+
+	a.esperar(a.fazer("GET", "/api/me", token, nil), http.StatusUnauthorized)
+	a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": sessao["refresh_token"]}), http.StatusUnauthorized)
+}
+
+func TestRefreshReutilizadoRevogaFamilia(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	sessao := a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusOK).JSON(t)
+	nova := a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": sessao["refresh_token"]}), http.StatusOK).JSON(t)
+
+	// Reapresentar o token já trocado derruba a cadeia inteira
+	a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": sessao["refresh_token"]}), http.StatusUnauthorized)
+	a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": nova["refresh_token"]}), http.StatusUnauthorized)
+}
+
+func TestXForwardedForNaoContornaLimiteDeLogin(t *testing.T) {
+	a := novoAmbiente(t)
+
+	// Um e-mail por tentativa, para só o limite por IP entrar em jogo. Sem
+	// proxies confiáveis cada tentativa conta para o IP da conexão,
+	// não para o que o cliente declara
+	status := 0
+	for i := 0; i < 25 && status != http.StatusTooManyRequests; i++ {
+		corpo, _ := json.Marshal(M{"email": fmt.Sprintf("cliente%d@calu.com", i), "senha": "errada123"})
+		req := httptest.NewRequest("POST", "/api/clients/login", bytes.NewReader(corpo))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+		w := httptest.NewRecorder()
+		a.router.ServeHTTP(w, req)
+		status = w.Code
+	}
+	if status != http.StatusTooManyRequests {
+		t.Fatalf("25 tentativas com X-Forwarded-For variado não foram limitadas (último status %d)", status)
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (h *Handler) GetDeliveryZones(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	zonas, err := h.zonas.List(ctx, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar zonas de entrega"})
+		return
+	}
+
+	c.JSON(http.StatusOK, zonas)
+}
+
+func (h *Handler) CreateDeliveryZone(c *gin.Context) {
+	var zona models.ZonaEntrega
+	if err := c.ShouldBindJSON(&zona); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zona.Normalizar()
+	if err := zona.Validar(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	zona.ID = bson.NewObjectID()
+
+	if err := h.zonas.Insert(context.Background(), zona); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar zona de entrega"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, zona)
+}
+
+func (h *Handler) UpdateDeliveryZone(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var zona models.ZonaEntrega
+	if err := c.ShouldBindJSON(&zona); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zona.Normalizar()
+	if err := zona.Validar(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	zona.ID = objID
+
+	err = h.zonas.Replace(context.Background(), zona)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zona de entrega não encontrada"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar zona de entrega"})
+		return
+	}
+
+	c.JSON(http.StatusOK, zona)
+}
+
+func (h *Handler) DeleteDeliveryZone(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	err = h.zonas.Delete(context.Background(), objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zona de entrega não encontrada"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir zona de entrega"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Zona de entrega excluída com sucesso"})
+}
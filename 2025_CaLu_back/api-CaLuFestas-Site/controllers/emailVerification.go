@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/otputil"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/tokenutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	validadeCodigoVerificacao = 30 * time.Minute
+	intervaloReenvio          = time.Minute
+	maxTentativasVerificacao  = 5
+)
+
+// enviarCodigoVerificacao gera um código novo para o e-mail, substituindo o
+// anterior, e o envia com SendOTP.
+func (h *Handler) enviarCodigoVerificacao(ctx context.Context, email string) error {
+	otp, err := otputil.GenerateOTP(6)
+	if err != nil {
+		return err
+	}
+
+	agora := time.Now().UTC()
+	entry := models.EmailVerification{
+		ID:        bson.NewObjectID(),
+		Email:     email,
+		OTPHash:   tokenutil.HashToken(otp),
+		SentAt:    agora,
+		ExpiresAt: agora.Add(validadeCodigoVerificacao),
+	}
+
+	if err := h.verificacoes.Replace(ctx, entry); err != nil {
+		return err
+	}
+
+	return h.emailSender.SendOTP(email, otp)
+}
+
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var request models.EmailVerificationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	request.Email = models.NormalizeEmail(request.Email)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry, err := h.verificacoes.FindByEmail(ctx, request.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhum código pendente para este e-mail", "code": "NO_PENDING_VERIFICATION"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if time.Now().UTC().After(entry.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Código expirado", "code": "OTP_EXPIRED"})
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(entry.OTPHash), []byte(tokenutil.HashToken(request.OTPCode))) != 1 {
+		atualizada, err := h.verificacoes.IncrementAttempts(ctx, entry.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		// Esgotadas as tentativas o código deixa de valer e é preciso pedir outro
+		if atualizada.Attempts >= maxTentativasVerificacao {
+			if err := h.verificacoes.Delete(ctx, entry.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Código invalidado após muitas tentativas, solicite outro", "code": "OTP_LOCKED"})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":                "Código inválido",
+			"code":                 "INVALID_OTP",
+			"tentativas_restantes": maxTentativasVerificacao - atualizada.Attempts,
+		})
+		return
+	}
+
+	client, err := h.clients.FindByEmail(ctx, request.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhum código pendente para este e-mail", "code": "NO_PENDING_VERIFICATION"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		ativada, err := h.clients.SetStatusIf(tx.Context(), client.ID, models.StatusPendente, models.StatusAtivo)
+		if err != nil {
+			return err
+		}
+		if ativada {
+			tx.OnRollback(func(ctx context.Context) error {
+				_, err := h.clients.SetStatusIf(ctx, client.ID, models.StatusAtivo, models.StatusPendente)
+				return err
+			})
+		}
+
+		return h.verificacoes.Delete(tx.Context(), entry.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "E-mail verificado com sucesso"})
+}
+
+// ResendVerification responde sempre da mesma forma, exista ou não uma conta
+// pendente, para não revelar quais e-mails estão cadastrados.
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var request models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	request.Email = models.NormalizeEmail(request.Email)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resposta := gin.H{"message": "Se houver uma conta pendente para este e-mail, um novo código foi enviado"}
+
+	client, err := h.clients.FindByEmail(ctx, request.Email)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && client.EmailVerificado()) {
+		c.JSON(http.StatusOK, resposta)
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	entry, err := h.verificacoes.FindByEmail(ctx, request.Email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if err == nil {
+		if espera := time.Until(entry.SentAt.Add(intervaloReenvio)); espera > 0 {
+			middle.TooManyRequests(c, espera)
+			return
+		}
+	}
+
+	if err := h.enviarCodigoVerificacao(ctx, client.Email); err != nil {
+		log.Printf("falha ao reenviar verificação para %s: %v", client.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error sending email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resposta)
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/config"
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/cep"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/email"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Dependencies é tudo de que os handlers precisam. Os repositórios podem ser
+// os do Mongo ou os em memória, o que permite testar os handlers só com
+// httptest.
+type Dependencies struct {
+	Config         config.Config
+	Tx             database.Transactor
+	Clients        repository.ClientRepository
+	Products       repository.ProductRepository
+	Locations      repository.LocationRepository
+	PasswordResets repository.PasswordResetRepository
+	Verifications  repository.EmailVerificationRepository
+	Tokens         repository.TokenRepository
+	Quotes         repository.QuoteRepository
+	PricingRules   repository.PricingRuleRepository
+	DeliveryZones  repository.DeliveryZoneRepository
+	EmailSender    email.EmailSender
+	Addresses      cep.AddressResolver
+	Limits         ratelimit.Store
+	// Readiness são as verificações do /readyz, pelo nome
+	Readiness map[string]Check
+}
+
+// Handler expõe os handlers HTTP como métodos, com as dependências
+// recebidas em New em vez de variáveis globais.
+type Handler struct {
+	cfg          config.Config
+	tx           database.Transactor
+	clients      repository.ClientRepository
+	products     repository.ProductRepository
+	locations    repository.LocationRepository
+	resets       repository.PasswordResetRepository
+	verificacoes repository.EmailVerificationRepository
+	tokens       repository.TokenRepository
+	cotacoes     repository.QuoteRepository
+	regras       repository.PricingRuleRepository
+	zonas        repository.DeliveryZoneRepository
+	emailSender  email.EmailSender
+	addresses    cep.AddressResolver
+	prontidao    map[string]Check
+	encerrando   atomic.Bool
+
+	// Limites por e-mail; os limites por IP ficam nas rotas
+	loginPorEmail *ratelimit.Limiter
+	resetPorEmail *ratelimit.Limiter
+}
+
+func New(deps Dependencies) *Handler {
+	return &Handler{
+		cfg:           deps.Config,
+		tx:            deps.Tx,
+		clients:       deps.Clients,
+		products:      deps.Products,
+		locations:     deps.Locations,
+		resets:        deps.PasswordResets,
+		verificacoes:  deps.Verifications,
+		tokens:        deps.Tokens,
+		cotacoes:      deps.Quotes,
+		regras:        deps.PricingRules,
+		zonas:         deps.DeliveryZones,
+		emailSender:   deps.EmailSender,
+		addresses:     deps.Addresses,
+		prontidao:     deps.Readiness,
+		loginPorEmail: ratelimit.NewLimiter(deps.Limits, "login-email", 5, 15*time.Minute),
+		resetPorEmail: ratelimit.NewLimiter(deps.Limits, "forgot-email", 3, time.Hour),
+	}
+}
+
+// NewMongo monta as dependências sobre um banco Mongo. O /readyz verifica o
+// Mongo e, quando o envio é por SMTP, se ele foi configurado.
+func NewMongo(cfg config.Config, client *mongo.Client, db *mongo.Database, sender email.EmailSender, addresses cep.AddressResolver, limits ratelimit.Store) *Handler {
+	readiness := map[string]Check{
+		"mongo": func(ctx context.Context) error {
+			return client.Ping(ctx, nil)
+		},
+	}
+	if smtp, ok := sender.(*email.Sender); ok {
+		readiness["smtp"] = func(context.Context) error {
+			return smtp.Configured()
+		}
+	}
+
+	return New(Dependencies{
+		Config:         cfg,
+		Tx:             database.NewMongoTransactor(client),
+		Clients:        repository.NewMongoClients(db),
+		Products:       repository.NewMongoProducts(db),
+		Locations:      repository.NewMongoLocations(db),
+		PasswordResets: repository.NewMongoPasswordResets(db),
+		Verifications:  repository.NewMongoEmailVerifications(db),
+		Tokens:         repository.NewMongoTokens(db),
+		Quotes:         repository.NewMongoQuotes(db),
+		PricingRules:   repository.NewMongoPricingRules(db),
+		DeliveryZones:  repository.NewMongoDeliveryZones(db),
+		EmailSender:    sender,
+		Addresses:      addresses,
+		Limits:         limits,
+		Readiness:      readiness,
+	})
+}
+
+// Clients e Tokens são expostos para o AuthMiddleware usar os mesmos
+// repositórios dos handlers.
+func (h *Handler) Clients() repository.ClientRepository {
+	return h.clients
+}
+
+func (h *Handler) Tokens() repository.TokenRepository {
+	return h.tokens
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check verifica uma dependência de que a API precisa para atender pedidos.
+type Check func(ctx context.Context) error
+
+// Healthz responde enquanto o processo estiver de pé; não olha dependências,
+// para que uma queda do Mongo não faça o orquestrador reiniciar a API.
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Encerrar tira a API do balanceamento: a partir daqui o /readyz responde
+// 503, enquanto as requisições em andamento terminam.
+func (h *Handler) Encerrar() {
+	h.encerrando.Store(true)
+}
+
+// Readyz executa as verificações de prontidão e responde 503 se alguma
+// falhar ou se a API estiver encerrando. A resposta diz só "ok" ou "falha"
+// por verificação; o motivo vai para o log, já que a rota é pública.
+func (h *Handler) Readyz(c *gin.Context) {
+	if h.encerrando.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "encerrando"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	status := http.StatusOK
+	resultados := gin.H{}
+	for nome, check := range h.prontidao {
+		if err := check(ctx); err != nil {
+			log.Printf("readyz: %s: %v", nome, err)
+			status = http.StatusServiceUnavailable
+			resultados[nome] = "falha"
+			continue
+		}
+		resultados[nome] = "ok"
+	}
+
+	if status != http.StatusOK {
+		c.JSON(status, gin.H{"status": "indisponível", "checks": resultados})
+		return
+	}
+	c.JSON(status, gin.H{"status": "ok", "checks": resultados})
+}
@@ -0,0 +1,61 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func prontidao(t *testing.T, checks map[string]controllers.Check) (*controllers.Handler, func() resposta) {
+	t.Helper()
+
+	h := controllers.New(controllers.Dependencies{Readiness: checks})
+	r := gin.New()
+	r.GET("/readyz", h.Readyz)
+	return h, func() resposta {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		return resposta{Code: w.Code, Body: w.Body.Bytes()}
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	falha := func(context.Context) error {
+		return errors.New("server selection error: mongo:27017 connection refused")
+	}
+
+	_, pronto := prontidao(t, map[string]controllers.Check{"mongo": ok})
+	r := pronto()
+	if r.Code != http.StatusOK || r.JSON(t)["checks"].(map[string]any)["mongo"] != "ok" {
+		t.Fatalf("resposta = %d %s", r.Code, r.Body)
+	}
+
+	_, pronto = prontidao(t, map[string]controllers.Check{"mongo": falha})
+	r = pronto()
+	if r.Code != http.StatusServiceUnavailable || r.JSON(t)["checks"].(map[string]any)["mongo"] != "falha" {
+		t.Fatalf("resposta = %d %s", r.Code, r.Body)
+	}
+	// O motivo da falha fica no log, não na rota pública
+	if strings.Contains(string(r.Body), "mongo:27017") {
+		t.Fatalf("resposta expõe o erro do driver: %s", r.Body)
+	}
+}
+
+func TestReadyzAoEncerrar(t *testing.T) {
+	h, pronto := prontidao(t, map[string]controllers.Check{
+		"mongo": func(context.Context) error { return nil },
+	})
+
+	h.Encerrar()
+	r := pronto()
+	if r.Code != http.StatusServiceUnavailable || r.JSON(t)["status"] != "encerrando" {
+		t.Fatalf("resposta = %d %s", r.Code, r.Body)
+	}
+}
@@ -0,0 +1,200 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/config"
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/Psnsilvino/CaluFestas-Site-api/routes"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/cep"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+}
+
+// emailFalso guarda o último código enviado para cada endereço.
+type emailFalso struct {
+	mu      sync.Mutex
+	codigos map[string]string
+}
+
+func (e *emailFalso) SendOTP(to, otp string) error {
+	return e.guardar(to, otp)
+}
+
+func (e *emailFalso) SendPasswordResetToken(to, token string) error {
+	return e.guardar(to, token)
+}
+
+func (e *emailFalso) guardar(to, codigo string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.codigos[to] = codigo
+	return nil
+}
+
+func (e *emailFalso) ultimo(to string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.codigos[to]
+}
+
+// ambiente é a API inteira montada sobre os repositórios em memória.
+type ambiente struct {
+	t        *testing.T
+	router   http.Handler
+	email    *emailFalso
+	clients  *repository.MemoryClients
+	products *repository.MemoryProducts
+	resets   *repository.MemoryPasswordResets
+}
+
+func novoAmbiente(t *testing.T, produtos ...models.Product) *ambiente {
+	t.Helper()
+
+	a := &ambiente{
+		t:        t,
+		email:    &emailFalso{codigos: map[string]string{}},
+		clients:  repository.NewMemoryClients(),
+		products: repository.NewMemoryProducts(produtos...),
+		resets:   repository.NewMemoryPasswordResets(),
+	}
+
+	cfg := config.Config{
+		Env:         "test",
+		JWTSecret:   "segredo-dos-testes-com-mais-de-32-caracteres",
+		CORSOrigins: []string{"http://localhost:5173"},
+	}
+	limits := ratelimit.NewMemoryStore()
+	h := controllers.New(controllers.Dependencies{
+		Config:         cfg,
+		Tx:             database.Compensating{},
+		Clients:        a.clients,
+		Products:       a.products,
+		Locations:      repository.NewMemoryLocations(),
+		PasswordResets: a.resets,
+		Verifications:  repository.NewMemoryEmailVerifications(),
+		Tokens:         repository.NewMemoryTokens(),
+		Quotes:         repository.NewMemoryQuotes(),
+		PricingRules:   repository.NewMemoryPricingRules(),
+		DeliveryZones:  repository.NewMemoryDeliveryZones(),
+		EmailSender:    a.email,
+		Addresses:      cep.Fixtures(),
+		Limits:         limits,
+	})
+	a.router = routes.SetupRouter(cfg, h, limits)
+	return a
+}
+
+// M é o corpo JSON de uma requisição ou resposta.
+type M = map[string]any
+
+type resposta struct {
+	Code int
+	Body []byte
+}
+
+// JSON decodifica o corpo da resposta; falha o teste se não for um objeto.
+func (r resposta) JSON(t *testing.T) M {
+	t.Helper()
+	var corpo M
+	if err := json.Unmarshal(r.Body, &corpo); err != nil {
+		t.Fatalf("resposta não é um objeto JSON: %s", r.Body)
+	}
+	return corpo
+}
+
+// JSONLista decodifica o corpo como uma lista de objetos.
+func (r resposta) JSONLista(t *testing.T) []M {
+	t.Helper()
+	var lista []M
+	if err := json.Unmarshal(r.Body, &lista); err != nil {
+		t.Fatalf("resposta não é uma lista JSON: %s", r.Body)
+	}
+	return lista
+}
+
+func (a *ambiente) fazer(method, path, token string, body any) resposta {
+	a.t.Helper()
+
+	var leitor *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		leitor = bytes.NewReader(b)
+	} else {
+		leitor = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, leitor)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return resposta{Code: w.Code, Body: w.Body.Bytes()}
+}
+
+// esperar falha o teste se o status não for o esperado.
+func (a *ambiente) esperar(r resposta, status int) resposta {
+	a.t.Helper()
+	if r.Code != status {
+		a.t.Fatalf("status %d, esperado %d: %s", r.Code, status, r.Body)
+	}
+	return r
+}
+
+// cadastrar cria a conta, confirma o e-mail e faz login, devolvendo o
+// access token.
+func (a *ambiente) cadastrar(nome, email, senha string) string {
+	a.t.Helper()
+
+	a.esperar(a.fazer("POST", "/api/clients/", "", M{"nome": nome, "email": email, "senha": senha}), http.StatusCreated)
+	a.esperar(a.fazer("POST", "/api/clients/verifyEmail", "", M{"email": email, "otp_code": a.email.ultimo(email)}), http.StatusOK)
+	return a.login(email, senha)
+}
+
+func (a *ambiente) login(email, senha string) string {
+	a.t.Helper()
+
+	corpo := a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": email, "senha": senha}), http.StatusOK).JSON(a.t)
+	token, _ := corpo["token"].(string)
+	if token == "" {
+		a.t.Fatalf("login sem token: %v", corpo)
+	}
+	return token
+}
+
+// cadastrarAdmin cria uma conta e a promove direto no repositório; o
+// AuthMiddleware lê o cargo atual a cada requisição.
+func (a *ambiente) cadastrarAdmin(nome, email, senha string) string {
+	a.t.Helper()
+
+	token := a.cadastrar(nome, email, senha)
+	client, err := a.clients.FindByEmail(context.Background(), email)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	cargo := models.CargoAdmin
+	if _, err := a.clients.Update(context.Background(), client.ID, repository.ClientChanges{Cargo: &cargo}); err != nil {
+		a.t.Fatal(err)
+	}
+	return token
+}
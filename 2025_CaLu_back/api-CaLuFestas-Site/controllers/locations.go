@@ -2,113 +2,223 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
 	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-func CreateLocation(c *gin.Context) {
-	var locacao models.Locacao
-	
-	if err := c.ShouldBindJSON(&locacao); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// erroHTTP interrompe uma unidade de trabalho levando a resposta que o
+// handler deve devolver depois que a transação for desfeita.
+type erroHTTP struct {
+	status int
+	body   gin.H
+}
+
+func (e *erroHTTP) Error() string {
+	return http.StatusText(e.status)
+}
+
+// viewsLocacoes converte uma lista de locações para a resposta; sem
+// locações, a lista vai vazia e não null.
+func viewsLocacoes(locacoes []models.Locacao) []models.LocacaoView {
+	views := make([]models.LocacaoView, len(locacoes))
+	for i, locacao := range locacoes {
+		views[i] = locacao.View()
+	}
+	return views
+}
+
+func responderErro(c *gin.Context, err error, mensagem string) {
+	var e *erroHTTP
+	if errors.As(err, &e) {
+		c.JSON(e.status, e.body)
 		return
 	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": mensagem})
+}
 
-	locacao.ID = primitive.NewObjectID()
-	locacao.Estado = "Em analise"
+// ajustarEstoque soma delta * quantidade ao contador de itens em locação de
+// cada produto, registrando a compensação para o caso de falha.
+func (h *Handler) ajustarEstoque(tx *database.Tx, items []models.Item, delta int) error {
+	for _, item := range items {
+		if item.ProdutoID.IsZero() {
+			return &erroHTTP{http.StatusUnprocessableEntity, gin.H{"error": "Item sem produto: " + item.Nome}}
+		}
 
-	for _, item := range locacao.Items {
-    	filter := bson.M{"nome": item.Nome}
-    	update := bson.M{
-        	"$inc": bson.M{
-        	    "quantidadeemlocacao": item.Quantidade, // subtrai do estoque
-        	},
-    	}
+		err := h.products.AdjustReserved(tx.Context(), item.ProdutoID, delta*item.Quantidade)
+		if errors.Is(err, repository.ErrNotFound) {
+			return &erroHTTP{http.StatusUnprocessableEntity, gin.H{"error": "Produto não encontrado: " + item.Nome}}
+		} else if errors.Is(err, repository.ErrArchived) {
+			return &erroHTTP{http.StatusUnprocessableEntity, gin.H{"error": "Produto fora do catálogo: " + item.Nome}}
+		} else if err != nil {
+			return err
+		}
 
-    	result := database.DB.Database(os.Getenv("DB_NAME")).Collection("produtos").FindOneAndUpdate(context.Background(), filter, update)
-    	if result.Err() != nil {
-        	c.JSON(http.StatusInternalServerError, gin.H{
-            	"error": item,
-        	})
-        	return
-    	}
+		produtoID, quantidade := item.ProdutoID, item.Quantidade
+		tx.OnRollback(func(ctx context.Context) error {
+			return h.products.AdjustReserved(ctx, produtoID, -delta*quantidade)
+		})
 	}
+	return nil
+}
 
-	_, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("locations").InsertOne(context.Background(), locacao)
+// checarDisponibilidade transforma uma falta de estoque em erro da unidade de trabalho.
+func (h *Handler) checarDisponibilidade(tx *database.Tx, locacao models.Locacao) error {
+	inicio, fim, err := locacao.Periodo()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create location"})
-		return
+		return &erroHTTP{http.StatusBadRequest, gin.H{"error": err.Error()}}
 	}
+	indisponiveis, err := h.verificarDisponibilidade(tx.Context(), locacao, inicio, fim)
+	if err != nil {
+		return err
+	}
+	if len(indisponiveis) > 0 {
+		return &erroHTTP{http.StatusConflict, gin.H{"error": "Itens indisponíveis para o período", "indisponiveis": indisponiveis}}
+	}
+	return nil
+}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Location registered successfully"})
+func (h *Handler) buscarLocacao(ctx context.Context, id bson.ObjectID) (models.Locacao, error) {
+	locacao, err := h.locations.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return locacao, &erroHTTP{http.StatusNotFound, gin.H{"error": "Locação não encontrada"}}
+	}
+	return locacao, err
 }
 
-func GetLocations(c *gin.Context) {
+func (h *Handler) CreateLocation(c *gin.Context) {
+	var input models.LocacaoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	locacao := input.Locacao()
+	if !h.prepararEndereco(c, &locacao) {
+		return
+	}
+	locacao.ID = bson.NewObjectID()
+	locacao.Estado = models.EstadoEmAnalise
+	locacao.Email = c.GetString("user")
+
+	inicio, fim, err := models.ParsePeriodo(locacao.DataEntrega, locacao.DataRetirada)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	locacao.Inicio = inicio
+	locacao.Fim = fim
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("locations").Find(ctx, bson.M{})
+	// Com uma cotação válida o preço cotado é mantido; sem ela, o valor é calculado agora
+	var orcamento models.Orcamento
+	var cotacao models.Cotacao
+	if locacao.Cotacao != "" {
+		cotacao, err = h.aplicarCotacao(ctx, &locacao)
+		orcamento = cotacao.Orcamento
+	} else {
+		orcamento, err = h.precificarLocacao(ctx, &locacao, inicio, fim)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		responderErro(c, err, "Erro ao calcular o valor da locação")
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var locations []models.Locacao
-	if err := cursor.All(ctx, &locations); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing products"})
+	// Com transações o $inc nos produtos também serve de trava: duas reservas
+	// simultâneas do mesmo produto geram conflito de escrita e a segunda
+	// transação é refeita, enxergando a primeira ao recalcular a
+	// disponibilidade. Sem transações (database.Compensating) não há essa
+	// proteção e as duas podem ser gravadas.
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		if err := h.checarDisponibilidade(tx, locacao); err != nil {
+			return err
+		}
+
+		if !cotacao.ID.IsZero() {
+			if err := h.consumirCotacao(tx, cotacao.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := h.ajustarEstoque(tx, locacao.Items, 1); err != nil {
+			return err
+		}
+
+		return h.locations.Insert(tx.Context(), locacao)
+	})
+	if err != nil {
+		responderErro(c, err, "Failed to create location")
 		return
 	}
 
-	c.JSON(http.StatusOK, locations)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Location registered successfully",
+		"_id":       locacao.ID,
+		"locacao":   locacao.View(),
+		"orcamento": orcamento,
+	})
 }
 
-func DeleteLocation(c *gin.Context) {
-	id := c.Param("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+func (h *Handler) GetLocations(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	locations, err := h.locations.List(ctx)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
 		return
 	}
 
-	var payload struct {
-		Items []models.Item `json:"items"`
-	}
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao processar itens enviados"})
+	c.JSON(http.StatusOK, viewsLocacoes(locations))
+}
+
+func (h *Handler) DeleteLocation(c *gin.Context) {
+	id := c.Param("id")
+	objID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
 		return
 	}
 
-	// Devolver itens ao estoque
-	for _, item := range payload.Items {
-		filter := bson.M{"nome": item.Nome}
-		update := bson.M{
-			"$inc": bson.M{
-				"quantidadeemlocacao": -item.Quantidade,
-			},
-		}
-		_, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("produtos").UpdateOne(context.Background(), filter, update)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		locacao, err := h.buscarLocacao(tx.Context(), objID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao devolver produto: " + item.Nome})
-			return
+			return err
 		}
-	}
 
-	// Deleta a locação
-	result, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("locations").DeleteOne(context.Background(), bson.M{"_id": objID})
+		// Devolver itens ao estoque, se a locação ainda os prende
+		if locacao.Estado.ReservaEstoque() {
+			if err := h.ajustarEstoque(tx, locacao.Items, -1); err != nil {
+				return err
+			}
+		}
+
+		// Deleta a locação
+		err = h.locations.Delete(tx.Context(), objID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return &erroHTTP{http.StatusNotFound, gin.H{"error": "Locação não encontrada"}}
+		} else if err != nil {
+			return err
+		}
+		tx.OnRollback(func(ctx context.Context) error {
+			return h.locations.Insert(ctx, locacao)
+		})
+		return nil
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao deletar locação"})
-		return
-	}
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Locação não encontrada"})
+		responderErro(c, err, "Erro ao deletar locação")
 		return
 	}
 
@@ -116,52 +226,85 @@ func DeleteLocation(c *gin.Context) {
 }
 
 
-func UpdateLocation(c *gin.Context) {
+func (h *Handler) UpdateLocation(c *gin.Context) {
 	id := c.Param("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+	objID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
 		return
 	}
 
-	var updatedData models.Locacao
-	if err := c.ShouldBindJSON(&updatedData); err != nil {
+	var payload struct {
+		Estado models.EstadoLocacao `json:"estado" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao fazer binding do JSON"})
 		return
 	}
+	if !payload.Estado.Valido() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estado desconhecido: " + string(payload.Estado)})
+		return
+	}
 
-	// Devolve itens ao estoque se a locação for recusada ou concluída
-	if updatedData.Estado == "Recusada" || updatedData.Estado == "Concluida" {
-		for _, item := range updatedData.Items {
-			filter := bson.M{"nome": item.Nome}
-			update := bson.M{
-				"$inc": bson.M{
-					"quantidadeemlocacao": -item.Quantidade,
-				},
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		atual, err := h.buscarLocacao(tx.Context(), objID)
+		if err != nil {
+			return err
+		}
+
+		if !middle.HasRole(c, models.CargoAdmin) {
+			if atual.Email != c.GetString("user") {
+				return &erroHTTP{http.StatusNotFound, gin.H{"error": "Locação não encontrada"}}
 			}
-			_, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("produtos").UpdateOne(context.Background(), filter, update)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao devolver produto: " + item.Nome})
-				return
+			if payload.Estado != models.EstadoCancelada {
+				return &erroHTTP{http.StatusForbidden, gin.H{"error": "Clientes só podem cancelar a locação"}}
 			}
 		}
-	}
 
-	// Atualiza estado da locação
-	update := bson.M{
-		"$set": bson.M{
-			"estado": updatedData.Estado,
-		},
-	}
+		if !atual.Estado.PodeIrPara(payload.Estado) {
+			return &erroHTTP{http.StatusConflict, gin.H{
+				"error":      "Transição de estado não permitida",
+				"estado":     atual.Estado,
+				"permitidos": atual.Estado.Proximos(),
+			}}
+		}
 
-	collection := database.DB.Database(os.Getenv("DB_NAME")).Collection("locations")
-	result, err := collection.UpdateOne(context.Background(), bson.M{"_id": objID}, update)
+		switch atual.Estado.EfeitoEstoque(payload.Estado) {
+		case -1:
+			// Devolve itens ao estoque quando a locação deixa de prendê-los
+			if err := h.ajustarEstoque(tx, atual.Items, -1); err != nil {
+				return err
+			}
+		case 1:
+			// Voltar a prender itens exige que o período ainda esteja livre
+			if err := h.checarDisponibilidade(tx, atual); err != nil {
+				return err
+			}
+			if err := h.ajustarEstoque(tx, atual.Items, 1); err != nil {
+				return err
+			}
+		}
+
+		// Atualiza estado da locação, desde que ninguém o tenha alterado no meio
+		// do caminho; isso garante que o efeito no estoque seja aplicado uma vez só
+		atualizada, err := h.locations.UpdateEstadoIf(tx.Context(), objID, atual.Estado, payload.Estado)
+		if err != nil {
+			return err
+		}
+		if !atualizada {
+			return &erroHTTP{http.StatusConflict, gin.H{"error": "A locação foi alterada por outra requisição"}}
+		}
+		tx.OnRollback(func(ctx context.Context) error {
+			_, err := h.locations.UpdateEstadoIf(ctx, objID, payload.Estado, atual.Estado)
+			return err
+		})
+		return nil
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar locação"})
-		return
-	}
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Locação não encontrada"})
+		responderErro(c, err, "Erro ao atualizar locação")
 		return
 	}
 
@@ -169,37 +312,46 @@ func UpdateLocation(c *gin.Context) {
 }
 
 
-func LocationsByClient(c *gin.Context) {
+func (h *Handler) LocationsByClient(c *gin.Context) {
+	// Só administradores consultam outros clientes; para os demais vale o
+	// e-mail do token e o corpo é ignorado. Um e-mail vazio traria as
+	// locações anonimizadas de contas excluídas.
 	var request models.ClientLocation
-
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cliente field is required"})
-		return
+	if middle.HasRole(c, models.CargoAdmin) {
+		if err := lerCorpo(c, &request); err != nil {
+			responderBind(c, err)
+			return
+		}
+	} else {
+		request.Email = models.NormalizeEmail(c.GetString("user"))
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"email": request.Email}
-
-	cursor, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("locations").Find(ctx, filter)
+	locations, _, err := h.locations.ListByEmail(ctx, request.Email, repository.Page{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch locations"})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var locations []models.Locacao
-	if err := cursor.All(ctx, &locations); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing locations"})
-		return
-	}
+	c.JSON(http.StatusOK, viewsLocacoes(locations))
+}
+
+// MyLocations lista, paginadas e das mais recentes para as mais antigas, as
+// locações do cliente autenticado.
+func (h *Handler) MyLocations(c *gin.Context) {
+	paginacao := lerPaginacao(c)
 
-	if len(locations) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": request})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	locations, total, err := h.locations.ListByEmail(ctx, c.GetString("user"), paginacao.Intervalo())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch locations"})
 		return
 	}
+	paginacao.Total = total
 
-	c.JSON(http.StatusOK, locations)
+	c.JSON(http.StatusOK, gin.H{"items": viewsLocacoes(locations), "paginacao": paginacao})
 }
-
@@ -0,0 +1,308 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestLocacaoNaoExcedeEstoque(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, pedido(mesa.ID, 2)), http.StatusCreated)
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, pedido(mesa.ID, 2)), http.StatusConflict)
+
+	// Outro período não concorre com a primeira locação
+	outroPeriodo := pedido(mesa.ID, 2)
+	outroPeriodo["data_entrega"] = "2030-02-01"
+	outroPeriodo["data_retirada"] = "2030-02-03"
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, outroPeriodo), http.StatusCreated)
+
+	produto, err := a.products.FindByID(context.Background(), mesa.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if produto.QuantidadeEmLocacao != 4 {
+		t.Fatalf("quantidade em locação = %d, esperado 4", produto.QuantidadeEmLocacao)
+	}
+}
+
+func TestPrecoVemDoCatalogo(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	barato := pedido(mesa.ID, 1)
+	barato["items"] = []M{{"_id": mesa.ID.Hex(), "quantidade": 1, "preco": 0.01, "nome": "Outra coisa"}}
+	locacao := a.esperar(a.fazer("POST", "/api/locations/", cliente, barato), http.StatusCreated).JSON(t)
+	if got := total(t, locacao); got != 40 {
+		t.Fatalf("total = %v, esperado 40 (preço do catálogo)", got)
+	}
+
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, pedido(bson.NewObjectID(), 1)), http.StatusUnprocessableEntity)
+}
+
+func TestMinhasLocacoes(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	bia := a.cadastrar("Bia", "bia@calu.com", "senha1234")
+
+	a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusCreated)
+
+	var pagina struct {
+		Items     []models.LocacaoView `json:"items"`
+		Paginacao struct {
+			Total int `json:"total"`
+		} `json:"paginacao"`
+	}
+	corpo := a.esperar(a.fazer("GET", "/api/me/locations", bia, nil), http.StatusOK).Body
+	if err := json.Unmarshal(corpo, &pagina); err != nil {
+		t.Fatal(err)
+	}
+	if pagina.Paginacao.Total != 0 {
+		t.Fatalf("Bia vê locações de outra conta: %s", corpo)
+	}
+
+	corpo = a.esperar(a.fazer("GET", "/api/me/locations", ana, nil), http.StatusOK).Body
+	if err := json.Unmarshal(corpo, &pagina); err != nil {
+		t.Fatal(err)
+	}
+	if pagina.Paginacao.Total != 1 || len(pagina.Items) != 1 {
+		t.Fatalf("esperada uma locação: %s", corpo)
+	}
+}
+
+func TestLocacoesPorClienteSemLocacoes(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	corpo := a.esperar(a.fazer("POST", "/api/locations/cliente", ana, nil), http.StatusOK).Body
+	if string(corpo) != "[]" {
+		t.Fatalf("esperada lista vazia, veio %s", corpo)
+	}
+	corpo = a.esperar(a.fazer("POST", "/api/locations/cliente", admin, M{"email": "ninguem@calu.com"}), http.StatusOK).Body
+	if string(corpo) != "[]" {
+		t.Fatalf("esperada lista vazia, veio %s", corpo)
+	}
+
+	a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusCreated)
+	var locacoes []models.LocacaoView
+	corpo = a.esperar(a.fazer("POST", "/api/locations/cliente", admin, M{"email": "ana@calu.com"}), http.StatusOK).Body
+	if err := json.Unmarshal(corpo, &locacoes); err != nil || len(locacoes) != 1 {
+		t.Fatalf("esperada uma locação da Ana: %s", corpo)
+	}
+}
+
+func emLocacao(t *testing.T, a *ambiente, id bson.ObjectID) int {
+	t.Helper()
+	produto, err := a.products.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return produto.QuantidadeEmLocacao
+}
+
+func TestConcluirDuasVezesDevolveEstoqueUmaVez(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	id := a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 2)), http.StatusCreated).JSON(t)["_id"].(string)
+	caminho := "/api/locations/" + id
+	for _, estado := range []models.EstadoLocacao{models.EstadoAprovada, models.EstadoEntregue, models.EstadoRetirada} {
+		a.esperar(a.fazer("PUT", caminho, admin, M{"estado": estado}), http.StatusOK)
+		if n := emLocacao(t, a, mesa.ID); n != 2 {
+			t.Fatalf("em locação = %d após %s, esperado 2", n, estado)
+		}
+	}
+
+	a.esperar(a.fazer("PUT", caminho, admin, M{"estado": models.EstadoConcluida}), http.StatusOK)
+	a.esperar(a.fazer("PUT", caminho, admin, M{"estado": models.EstadoConcluida}), http.StatusConflict)
+	if n := emLocacao(t, a, mesa.ID); n != 0 {
+		t.Fatalf("em locação = %d, esperado 0", n)
+	}
+}
+
+func TestTransicaoNaoPermitida(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	id := a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 2)), http.StatusCreated).JSON(t)["_id"].(string)
+
+	corpo := a.esperar(a.fazer("PUT", "/api/locations/"+id, admin, M{"estado": models.EstadoConcluida}), http.StatusConflict).JSON(t)
+	permitidos, _ := json.Marshal(corpo["permitidos"])
+	if corpo["estado"] != string(models.EstadoEmAnalise) || string(permitidos) != `["Aprovada","Recusada","Cancelada"]` {
+		t.Fatalf("resposta = %v", corpo)
+	}
+	if n := emLocacao(t, a, mesa.ID); n != 2 {
+		t.Fatalf("em locação = %d, esperado 2", n)
+	}
+
+	a.esperar(a.fazer("PUT", "/api/locations/"+id, admin, M{"estado": "Pendente"}), http.StatusBadRequest)
+}
+
+func TestClienteSoCancelaAPropriaLocacao(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	bia := a.cadastrar("Bia", "bia@calu.com", "senha1234")
+
+	id := a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 2)), http.StatusCreated).JSON(t)["_id"].(string)
+	caminho := "/api/locations/" + id
+
+	a.esperar(a.fazer("PUT", caminho, ana, M{"estado": models.EstadoAprovada}), http.StatusForbidden)
+	// Para quem não é dono a locação não existe, nem para cancelar
+	a.esperar(a.fazer("PUT", caminho, bia, M{"estado": models.EstadoCancelada}), http.StatusNotFound)
+	a.esperar(a.fazer("PUT", caminho, bia, M{"estado": models.EstadoAprovada}), http.StatusNotFound)
+	if n := emLocacao(t, a, mesa.ID); n != 2 {
+		t.Fatalf("em locação = %d, esperado 2", n)
+	}
+
+	a.esperar(a.fazer("PUT", caminho, ana, M{"estado": models.EstadoCancelada}), http.StatusOK)
+	if n := emLocacao(t, a, mesa.ID); n != 0 {
+		t.Fatalf("em locação = %d após o cancelamento, esperado 0", n)
+	}
+}
+
+func TestLocacoesPorClienteExigeEmailDoAdmin(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+
+	// Contas excluídas deixam locações com e-mail vazio
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	id := a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusCreated).JSON(t)["_id"].(string)
+	a.esperar(a.fazer("PUT", "/api/locations/"+id, ana, M{"estado": models.EstadoCancelada}), http.StatusOK)
+	a.esperar(a.fazer("DELETE", "/api/me", ana, M{"senha": "senha1234"}), http.StatusOK)
+
+	for _, corpo := range []M{{}, {"email": ""}, {"email": "   "}, {"email": "ana"}} {
+		erro := a.esperar(a.fazer("POST", "/api/locations/cliente", admin, corpo), http.StatusUnprocessableEntity).JSON(t)
+		campos, _ := erro["fields"].(M)
+		if erro["code"] != "VALIDATION_ERROR" || campos["email"] == nil {
+			t.Fatalf("corpo %v: resposta = %v", corpo, erro)
+		}
+	}
+	a.esperar(a.fazer("POST", "/api/locations/cliente", admin, nil), http.StatusBadRequest)
+
+	corpo := a.esperar(a.fazer("POST", "/api/locations/cliente", admin, M{"email": " Ana@Calu.com "}), http.StatusOK).Body
+	if string(corpo) != "[]" {
+		t.Fatalf("locações anonimizadas ainda ligadas à Ana: %s", corpo)
+	}
+}
+
+// As rotas de locação devolvem models.LocacaoView, e não o documento gravado.
+func TestRespostasDeLocacaoUsamAView(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	chaves := func(locacao any) string {
+		campos, ok := locacao.(M)
+		if !ok {
+			t.Fatalf("locação não é um objeto: %v", locacao)
+		}
+		var lista []string
+		for chave := range campos {
+			lista = append(lista, chave)
+		}
+		sort.Strings(lista)
+		return strings.Join(lista, ",")
+	}
+	esperado := "_id,data_entrega,data_retirada,email,endereco,estado,fim,frete,inicio,items,nome,pagamento,total"
+
+	criada := a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusCreated).JSON(t)
+	if got := chaves(criada["locacao"]); got != esperado {
+		t.Fatalf("POST /api/locations/: %s", got)
+	}
+
+	lista := func(r resposta) []any {
+		var l []any
+		for _, locacao := range r.JSONLista(t) {
+			l = append(l, locacao)
+		}
+		return l
+	}
+	pagina := func(r resposta) []any {
+		l, _ := r.JSON(t)["items"].([]any)
+		return l
+	}
+	id := a.esperar(a.fazer("GET", "/api/me", ana, nil), http.StatusOK).JSON(t)["_id"].(string)
+
+	listas := map[string]func() []any{
+		"GET /api/locations/": func() []any {
+			return lista(a.esperar(a.fazer("GET", "/api/locations/", admin, nil), http.StatusOK))
+		},
+		"POST /api/locations/cliente": func() []any {
+			return lista(a.esperar(a.fazer("POST", "/api/locations/cliente", admin, M{"email": "ana@calu.com"}), http.StatusOK))
+		},
+		"GET /api/me/locations": func() []any {
+			return pagina(a.esperar(a.fazer("GET", "/api/me/locations", ana, nil), http.StatusOK))
+		},
+		"GET /api/privateClients/:id": func() []any {
+			locacoes, _ := a.esperar(a.fazer("GET", "/api/privateClients/"+id, admin, nil), http.StatusOK).JSON(t)["locacoes"].(M)
+			l, _ := locacoes["items"].([]any)
+			return l
+		},
+	}
+	for rota, listar := range listas {
+		locacoes := listar()
+		if len(locacoes) != 1 {
+			t.Fatalf("%s: %d locações", rota, len(locacoes))
+		}
+		if got := chaves(locacoes[0]); got != esperado {
+			t.Errorf("%s: %s", rota, got)
+		}
+	}
+}
+
+func TestEnderecoDaLocacaoVemDoCEP(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	inventado := pedido(mesa.ID, 1)
+	inventado["endereco_entrega"] = M{"cep": "01310-100", "logradouro": "Rua Inventada", "numero": "1000", "bairro": "Centro", "cidade": "Campinas", "uf": "rj"}
+	locacao := a.esperar(a.fazer("POST", "/api/locations/", ana, inventado), http.StatusCreated).JSON(t)["locacao"].(M)
+	endereco := locacao["endereco_entrega"].(M)
+	if endereco["cep"] != "01310100" || endereco["logradouro"] != "Avenida Paulista" || endereco["bairro"] != "Bela Vista" ||
+		endereco["cidade"] != "São Paulo" || endereco["uf"] != "SP" || endereco["numero"] != "1000" {
+		t.Fatalf("endereço = %v", endereco)
+	}
+	if locacao["endereco"] != "Avenida Paulista, 1000 - Bela Vista, São Paulo/SP - CEP 01310-100" {
+		t.Fatalf("endereço em texto = %v", locacao["endereco"])
+	}
+
+	// Basta o CEP e o número
+	soCEP := pedido(mesa.ID, 1)
+	soCEP["endereco_entrega"] = M{"cep": "20010000", "numero": "5"}
+	a.esperar(a.fazer("POST", "/api/locations/", ana, soCEP), http.StatusCreated)
+
+	desconhecido := pedido(mesa.ID, 1)
+	desconhecido["endereco_entrega"] = M{"cep": "99999-999", "logradouro": "Rua Inventada", "numero": "1", "bairro": "Centro", "cidade": "São Paulo", "uf": "SP"}
+	corpo := a.esperar(a.fazer("POST", "/api/locations/", ana, desconhecido), http.StatusUnprocessableEntity).JSON(t)
+	if corpo["code"] != "CEP_NOT_FOUND" || corpo["fields"].(M)["endereco_entrega.cep"] == nil {
+		t.Fatalf("resposta = %v", corpo)
+	}
+
+	semNumero := pedido(mesa.ID, 1)
+	semNumero["endereco_entrega"] = M{"cep": "01310-100"}
+	campos := a.esperar(a.fazer("POST", "/api/locations/", ana, semNumero), http.StatusUnprocessableEntity).JSON(t)["fields"].(M)
+	if campos["endereco_entrega.numero"] == nil || len(campos) != 1 {
+		t.Fatalf("campos = %v", campos)
+	}
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	limitePadrao = 20
+	limiteMaximo = 100
+)
+
+type Paginacao struct {
+	Page  int64 `json:"page"`
+	Limit int64 `json:"limit"`
+	Total int64 `json:"total"`
+}
+
+// lerPaginacao lê ?page= e ?limit=, corrigindo valores ausentes ou fora da faixa.
+func lerPaginacao(c *gin.Context) Paginacao {
+	page, err := strconv.ParseInt(c.Query("page"), 10, 64)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.ParseInt(c.Query("limit"), 10, 64)
+	if err != nil || limit < 1 {
+		limit = limitePadrao
+	}
+	if limit > limiteMaximo {
+		limit = limiteMaximo
+	}
+	return Paginacao{Page: page, Limit: limit}
+}
+
+// Intervalo converte a paginação para o formato dos repositórios.
+func (p Paginacao) Intervalo() repository.Page {
+	return repository.Page{Skip: (p.Page - 1) * p.Limit, Limit: p.Limit}
+}
@@ -0,0 +1,126 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/tokenutil"
+)
+
+// codigoErrado devolve um código de mesmo tamanho garantidamente diferente.
+func codigoErrado(codigo string) string {
+	b := []byte(codigo)
+	b[len(b)-1] = '0' + (b[len(b)-1]-'0'+1)%10
+	return string(b)
+}
+
+// pedirReset dispara o ForgotPassword e devolve o OTP enviado por e-mail.
+func (a *ambiente) pedirReset(email string) string {
+	a.t.Helper()
+	a.esperar(a.fazer("POST", "/api/clients/ForgotPassword", "", M{"email": email}), http.StatusCreated)
+	return a.email.ultimo(email)
+}
+
+// confirmarReset troca o OTP pelo reset token.
+func (a *ambiente) confirmarReset(email, otp string) string {
+	a.t.Helper()
+	corpo := a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", M{"email": email, "otp_code": otp}), http.StatusOK).JSON(a.t)
+	return corpo["reset_token"].(string)
+}
+
+func TestRedefinicaoDeSenhaNaoAceitaReplay(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	otp := a.pedirReset("ana@calu.com")
+	token := a.confirmarReset("ana@calu.com", otp)
+
+	// O mesmo OTP não gera um segundo reset token
+	a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", M{"email": "ana@calu.com", "otp_code": otp}), http.StatusUnauthorized)
+
+	troca := M{"email": "ana@calu.com", "reset_token": token, "password": "novaSenha123"}
+	a.esperar(a.fazer("POST", "/api/clients/ResetPassword", "", troca), http.StatusOK)
+	a.esperar(a.fazer("POST", "/api/clients/ResetPassword", "", M{"email": "ana@calu.com", "reset_token": token, "password": "outraSenha123"}), http.StatusUnauthorized)
+
+	a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusUnauthorized)
+	a.login("ana@calu.com", "novaSenha123")
+}
+
+func TestOTPExpirado(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	otp := a.pedirReset("ana@calu.com")
+
+	ctx := context.Background()
+	pedido, err := a.resets.FindPending(ctx, "ana@calu.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pedido.ExpiresAt = time.Now().UTC().Add(-time.Second)
+	if err := a.resets.Replace(ctx, pedido); err != nil {
+		t.Fatal(err)
+	}
+
+	a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", M{"email": "ana@calu.com", "otp_code": otp}), http.StatusBadRequest)
+}
+
+func TestResetTokenExpirado(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	token := a.confirmarReset("ana@calu.com", a.pedirReset("ana@calu.com"))
+
+	ctx := context.Background()
+	pedido, err := a.resets.FindByResetToken(ctx, "ana@calu.com", tokenutil.HashToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pedido.ExpiresAt = time.Now().UTC().Add(-time.Second)
+	if err := a.resets.Replace(ctx, pedido); err != nil {
+		t.Fatal(err)
+	}
+
+	a.esperar(a.fazer("POST", "/api/clients/ResetPassword", "", M{"email": "ana@calu.com", "reset_token": token, "password": "novaSenha123"}), http.StatusUnauthorized)
+	a.login("ana@calu.com", "senha1234")
+}
+
+func TestResetDeOutraConta(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	a.cadastrar("Bia", "bia@calu.com", "senha1234")
+
+	otpAna := a.pedirReset("ana@calu.com")
+
+	// Bia não pediu redefinição, então o código da Ana não vale para ela
+	a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", M{"email": "bia@calu.com", "otp_code": otpAna}), http.StatusUnauthorized)
+
+	otpBia := a.pedirReset("bia@calu.com")
+	if otpBia != otpAna {
+		a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", M{"email": "bia@calu.com", "otp_code": otpAna}), http.StatusBadRequest)
+	}
+
+	// O reset token da Ana não troca a senha da Bia
+	tokenAna := a.confirmarReset("ana@calu.com", otpAna)
+	a.esperar(a.fazer("POST", "/api/clients/ResetPassword", "", M{"email": "bia@calu.com", "reset_token": tokenAna, "password": "invasor123"}), http.StatusUnauthorized)
+	a.login("bia@calu.com", "senha1234")
+
+	// E continua valendo para a própria Ana
+	a.esperar(a.fazer("POST", "/api/clients/ResetPassword", "", M{"email": "ana@calu.com", "reset_token": tokenAna, "password": "novaSenha123"}), http.StatusOK)
+}
+
+func TestOTPBloqueiaAposTentativasErradas(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	otp := a.pedirReset("ana@calu.com")
+
+	errado := M{"email": "ana@calu.com", "otp_code": codigoErrado(otp)}
+	for i := 1; i < 5; i++ {
+		a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", errado), http.StatusBadRequest)
+	}
+	a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", errado), http.StatusTooManyRequests)
+
+	// Bloqueado, nem o código certo nem um código novo passam
+	a.esperar(a.fazer("POST", "/api/clients/verifyCode", "", M{"email": "ana@calu.com", "otp_code": otp}), http.StatusTooManyRequests)
+	a.esperar(a.fazer("POST", "/api/clients/ForgotPassword", "", M{"email": "ana@calu.com"}), http.StatusTooManyRequests)
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/pricing"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// precificarLocacao ignora os preços enviados pelo cliente: cada item é
+// buscado no catálogo pelo ID, as regras de preço ativas são aplicadas e a
+// locação recebe o nome, o preço e o total calculados pelo servidor.
+func (h *Handler) precificarLocacao(ctx context.Context, locacao *models.Locacao, inicio, fim time.Time) (models.Orcamento, error) {
+	var linhas []pricing.Linha
+	for _, item := range locacao.Items {
+		if item.Quantidade <= 0 {
+			return models.Orcamento{}, &erroHTTP{http.StatusBadRequest, gin.H{"error": "Quantidade inválida para o item: " + item.Nome}}
+		}
+
+		if item.ProdutoID.IsZero() {
+			return models.Orcamento{}, &erroHTTP{http.StatusBadRequest, gin.H{"error": "Item sem produto: " + item.Nome}}
+		}
+
+		produto, err := h.products.FindByID(ctx, item.ProdutoID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.Orcamento{}, &erroHTTP{http.StatusUnprocessableEntity, gin.H{"error": "Produto não encontrado: " + item.Nome}}
+		} else if err != nil {
+			return models.Orcamento{}, err
+		}
+		if produto.Arquivado {
+			return models.Orcamento{}, &erroHTTP{http.StatusUnprocessableEntity, gin.H{"error": "Produto fora do catálogo: " + produto.Nome}}
+		}
+
+		linhas = append(linhas, pricing.Linha{Produto: produto, Quantidade: item.Quantidade})
+	}
+
+	regras, err := h.regras.List(ctx, true)
+	if err != nil {
+		return models.Orcamento{}, err
+	}
+
+	orcamento := pricing.Calcular(inicio, fim, linhas, regras)
+	for i, cobrado := range orcamento.Itens {
+		locacao.Items[i].Nome = cobrado.Nome
+		locacao.Items[i].Preco = cobrado.PrecoUnitario
+	}
+
+	frete, err := h.calcularFrete(ctx, locacao.EnderecoEntrega)
+	if err != nil {
+		return models.Orcamento{}, err
+	}
+	if frete != nil {
+		pricing.AplicarFrete(&orcamento, *frete)
+		locacao.Frete = frete.Total
+	}
+	locacao.Total = orcamento.Total
+
+	return orcamento, nil
+}
+
+// calcularFrete devolve nil enquanto nenhuma zona de entrega estiver ativa,
+// para que locações sem endereço estruturado continuem funcionando até as
+// zonas serem cadastradas.
+func (h *Handler) calcularFrete(ctx context.Context, endereco *models.Endereco) (*models.Frete, error) {
+	zonas, err := h.zonas.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(zonas) == 0 {
+		return nil, nil
+	}
+
+	if endereco == nil {
+		return nil, &erroHTTP{http.StatusUnprocessableEntity, gin.H{
+			"error":  "Informe o endereço de entrega para calcular o frete",
+			"code":   "VALIDATION_ERROR",
+			"fields": gin.H{"endereco_entrega": "campo obrigatório"},
+		}}
+	}
+
+	// Só o que veio do serviço de CEP decide a zona: bairro, cidade e UF
+	// digitados pelo cliente podem ser qualquer coisa.
+	resolvido, err := h.addresses.Resolve(ctx, endereco.CEP)
+	if err != nil {
+		return nil, erroCEP(err, "endereco_entrega.cep")
+	}
+	local := models.Endereco{CEP: resolvido.CEP, Bairro: resolvido.Bairro, Cidade: resolvido.Cidade, UF: resolvido.UF}
+	local.Normalizar()
+
+	frete, err := pricing.CalcularFrete(local, zonas)
+	if errors.Is(err, pricing.ErrForaDaArea) {
+		return nil, &erroHTTP{http.StatusUnprocessableEntity, gin.H{"error": "Não entregamos neste endereço", "code": "OUT_OF_DELIVERY_AREA"}}
+	} else if err != nil {
+		return nil, err
+	}
+	return &frete, nil
+}
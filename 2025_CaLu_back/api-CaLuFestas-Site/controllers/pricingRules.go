@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (h *Handler) GetPricingRules(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	regras, err := h.regras.List(ctx, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar regras de preço"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regras)
+}
+
+func (h *Handler) CreatePricingRule(c *gin.Context) {
+	var regra models.RegraPreco
+	if err := c.ShouldBindJSON(&regra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := regra.Validar(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	regra.ID = bson.NewObjectID()
+
+	if err := h.regras.Insert(context.Background(), regra); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar regra de preço"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, regra)
+}
+
+func (h *Handler) UpdatePricingRule(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var regra models.RegraPreco
+	if err := c.ShouldBindJSON(&regra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := regra.Validar(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	regra.ID = objID
+
+	err = h.regras.Replace(context.Background(), regra)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Regra de preço não encontrada"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar regra de preço"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regra)
+}
+
+func (h *Handler) DeletePricingRule(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	err = h.regras.Delete(context.Background(), objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Regra de preço não encontrada"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir regra de preço"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Regra de preço excluída com sucesso"})
+}
@@ -2,51 +2,217 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/Psnsilvino/CaluFestas-Site-api/database"
 	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-func GetProducts(c *gin.Context) {
+func (h *Handler) GetProducts(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("produtos").Find(ctx, bson.M{})
+	products, err := h.products.List(ctx, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var products []models.Product
-	if err := cursor.All(ctx, &products); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing products"})
+	views := make([]models.ProductView, len(products))
+	for i, product := range products {
+		views[i] = product.View()
+	}
+
+	c.JSON(http.StatusOK, views)
+}
+
+// GetAllProducts lista também os produtos arquivados, para o painel administrativo.
+func (h *Handler) GetAllProducts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	products, err := h.products.List(ctx, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
 		return
 	}
 
 	c.JSON(http.StatusOK, products)
 }
 
-func CreateProduct(c *gin.Context) {
-	var product models.Product
-	if err := c.ShouldBindJSON(&product); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (h *Handler) GetProduct(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
 		return
 	}
 
-	product.ID = primitive.NewObjectID()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	_, err := database.DB.Database(os.Getenv("DB_NAME")).Collection("produtos").InsertOne(context.Background(), product)
-	if err != nil {
+	product, err := h.products.FindByID(ctx, objID)
+	if err == nil && product.Arquivado {
+		err = repository.ErrNotFound
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product.View())
+}
+
+func (h *Handler) CreateProduct(c *gin.Context) {
+	var input models.ProductInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	product := models.Product{ID: bson.NewObjectID()}
+	input.Patch().Aplicar(&product)
+	if err := product.Validar(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.products.Insert(context.Background(), product); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Product registered successfully"})
-}
\ No newline at end of file
+	c.JSON(http.StatusCreated, gin.H{"message": "Product registered successfully", "_id": product.ID})
+}
+
+func (h *Handler) UpdateProduct(c *gin.Context) {
+	var input models.ProductInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	h.editarProduto(c, input.Patch())
+}
+
+func (h *Handler) PatchProduct(c *gin.Context) {
+	var patch models.ProductPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	h.editarProduto(c, patch)
+}
+
+// editarProduto grava só os campos editáveis, sem sobrescrever o contador de
+// itens em locação que as locações alteram em paralelo.
+func (h *Handler) editarProduto(c *gin.Context, patch models.ProductPatch) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	product, err := h.products.FindByID(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	patch.Aplicar(&product)
+	if err := product.Validar(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Arquivado e quantidade em locação são conferidos na própria escrita,
+	// já que arquivamento e locações podem mudar o produto desde a leitura
+	err = h.products.UpdateFields(ctx, product)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
+		return
+	case errors.Is(err, repository.ErrArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": "Produto arquivado; restaure-o antes de editar"})
+		return
+	case errors.Is(err, repository.ErrBelowReserved):
+		c.JSON(http.StatusConflict, gin.H{"error": "Quantidade menor que as unidades em locação", "quantidadeemlocacao": product.QuantidadeEmLocacao})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar produto"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// ArchiveProduct retira o produto do catálogo sem apagá-lo, já que locações
+// antigas continuam apontando para ele.
+func (h *Handler) ArchiveProduct(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Contagem e arquivamento na mesma transação: uma locação criada entre os
+	// dois também escreve no produto, e uma das transações é refeita
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		ativas, err := h.locations.CountActiveByProduct(tx.Context(), objID)
+		if err != nil {
+			return err
+		}
+		if ativas > 0 {
+			return &erroHTTP{http.StatusConflict, gin.H{"error": "Produto possui locações em andamento", "locacoes": ativas}}
+		}
+
+		agora := time.Now().UTC()
+		err = h.products.SetArchived(tx.Context(), objID, &agora)
+		if errors.Is(err, repository.ErrNotFound) {
+			return &erroHTTP{http.StatusNotFound, gin.H{"error": "Produto não encontrado"}}
+		}
+		return err
+	})
+	if err != nil {
+		responderErro(c, err, "Erro ao arquivar produto")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produto arquivado com sucesso"})
+}
+
+func (h *Handler) RestoreProduct(c *gin.Context) {
+	objID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	err = h.products.SetArchived(context.Background(), objID, nil)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao restaurar produto"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produto restaurado com sucesso"})
+}
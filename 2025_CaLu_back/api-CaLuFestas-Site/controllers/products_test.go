@@ -0,0 +1,102 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestEditarProduto(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	caminho := "/api/privateProducts/" + mesa.ID.Hex()
+
+	a.esperar(a.fazer("PATCH", caminho, cliente, M{"preco": 30}), http.StatusForbidden)
+	a.esperar(a.fazer("PATCH", "/api/privateProducts/"+bson.NewObjectID().Hex(), admin, M{"preco": 30}), http.StatusNotFound)
+
+	editado := a.esperar(a.fazer("PATCH", caminho, admin, M{"preco": 30}), http.StatusOK).JSON(t)
+	if editado["preco"] != 30.0 || editado["nome"] != "Mesa" {
+		t.Fatalf("PATCH alterou mais do que o enviado: %v", editado)
+	}
+
+	// Com 3 mesas locadas o estoque não pode cair para 2
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, pedido(mesa.ID, 3)), http.StatusCreated)
+	a.esperar(a.fazer("PATCH", caminho, admin, M{"quantidade": 2}), http.StatusConflict)
+	a.esperar(a.fazer("PATCH", caminho, admin, M{"quantidade": 3}), http.StatusOK)
+}
+
+func TestProdutoArquivadoNaoEEditado(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	caminho := "/api/privateProducts/" + mesa.ID.Hex()
+
+	a.esperar(a.fazer("DELETE", caminho, admin, nil), http.StatusOK)
+	a.esperar(a.fazer("PATCH", caminho, admin, M{"preco": 30}), http.StatusConflict)
+
+	a.esperar(a.fazer("POST", caminho+"/restore", admin, nil), http.StatusOK)
+	a.esperar(a.fazer("PATCH", caminho, admin, M{"preco": 30}), http.StatusOK)
+}
+
+func TestDisponibilidadeDoProduto(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	caminho := "/api/products/" + mesa.ID.Hex() + "/availability"
+
+	// pedido reserva de 08/01 a 10/01
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, pedido(mesa.ID, 2)), http.StatusCreated)
+
+	d := a.esperar(a.fazer("GET", caminho+"?from=2030-01-09&to=2030-01-12", "", nil), http.StatusOK).JSON(t)
+	if d["produto_id"] != mesa.ID.Hex() || d["quantidade"] != 5.0 || d["reservada"] != 2.0 || d["disponivel"] != 3.0 {
+		t.Fatalf("disponibilidade = %v", d)
+	}
+	if _, ok := d["solicitada"]; ok {
+		t.Fatalf("solicitada só aparece em conflitos: %v", d)
+	}
+
+	// A retirada no dia 10 libera as mesas para quem recebe no dia 10
+	d = a.esperar(a.fazer("GET", caminho+"?from=2030-01-10&to=2030-01-12", "", nil), http.StatusOK).JSON(t)
+	if d["disponivel"] != 5.0 {
+		t.Fatalf("disponibilidade = %v", d)
+	}
+
+	a.esperar(a.fazer("GET", caminho+"?from=2030-01-12&to=2030-01-10", "", nil), http.StatusBadRequest)
+	a.esperar(a.fazer("GET", "/api/products/"+bson.NewObjectID().Hex()+"/availability?from=2030-01-09&to=2030-01-12", "", nil), http.StatusNotFound)
+}
+
+func TestArquivarProdutoComLocacao(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	caminho := "/api/privateProducts/" + mesa.ID.Hex()
+
+	id := a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusCreated).JSON(t)["_id"].(string)
+	corpo := a.esperar(a.fazer("DELETE", caminho, admin, nil), http.StatusConflict).JSON(t)
+	if corpo["locacoes"] != 1.0 {
+		t.Fatalf("resposta = %v", corpo)
+	}
+
+	a.esperar(a.fazer("PUT", "/api/locations/"+id, ana, M{"estado": models.EstadoCancelada}), http.StatusOK)
+	a.esperar(a.fazer("DELETE", caminho, admin, nil), http.StatusOK)
+	a.esperar(a.fazer("DELETE", "/api/privateProducts/"+bson.NewObjectID().Hex(), admin, nil), http.StatusNotFound)
+
+	// Arquivado, o produto não entra em locações novas
+	a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusUnprocessableEntity)
+}
+
+func TestEditarProdutoComCorpoInvalido(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+
+	corpo := a.esperar(a.fazer("PATCH", "/api/privateProducts/"+mesa.ID.Hex(), admin, M{"preco": "caro"}), http.StatusBadRequest).JSON(t)
+	if corpo["code"] != "INVALID_BODY" {
+		t.Fatalf("resposta = %v", corpo)
+	}
+}
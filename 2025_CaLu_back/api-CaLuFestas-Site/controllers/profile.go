@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/docutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// clienteAutenticado busca o cliente do token; o AuthMiddleware já garantiu
+// que ele existe, então a ausência aqui é tratada como sessão inválida.
+func (h *Handler) clienteAutenticado(ctx context.Context, c *gin.Context) (models.Client, bool) {
+	client, err := h.clients.FindByEmail(ctx, c.GetString("user"))
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return client, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return client, false
+	}
+	return client, true
+}
+
+func (h *Handler) GetProfile(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, ok := h.clienteAutenticado(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, client.View())
+}
+
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	var patch models.ProfilePatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	var changes repository.ClientChanges
+	campos := map[string]string{}
+
+	if patch.Nome != nil {
+		nome := strings.TrimSpace(*patch.Nome)
+		if nome == "" {
+			campos["nome"] = "campo obrigatório"
+		}
+		changes.Nome = &nome
+	}
+	// String vazia remove o telefone/documento do perfil
+	if patch.Telefone != nil {
+		telefone := ""
+		if strings.TrimSpace(*patch.Telefone) != "" {
+			var err error
+			if telefone, err = docutil.Telefone(*patch.Telefone); err != nil {
+				campos["telefone"] = err.Error()
+			}
+		}
+		changes.Telefone = &telefone
+	}
+	if patch.Documento != nil {
+		documento := ""
+		if strings.TrimSpace(*patch.Documento) != "" {
+			var err error
+			if documento, _, err = docutil.Documento(*patch.Documento); err != nil {
+				campos["documento"] = err.Error()
+			}
+		}
+		changes.Documento = &documento
+	}
+	if patch.Enderecos != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+		defer cancel()
+
+		enderecos := make([]models.EnderecoSalvo, len(*patch.Enderecos))
+		for i, endereco := range *patch.Enderecos {
+			prefixo := fmt.Sprintf("enderecos[%d].", i)
+			if err := h.conferirEndereco(ctx, &endereco.Endereco, prefixo, campos); err != nil {
+				responderErroCEP(c, err, prefixo+"cep")
+				return
+			}
+			enderecos[i] = models.EnderecoSalvo{
+				ID:       endereco.ID,
+				Apelido:  strings.TrimSpace(endereco.Apelido),
+				Endereco: endereco.Endereco,
+			}
+		}
+		changes.Enderecos = &enderecos
+	}
+
+	if len(campos) > 0 {
+		responderCamposInvalidos(c, campos)
+		return
+	}
+	if changes == (repository.ClientChanges{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhum campo para atualizar"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, ok := h.clienteAutenticado(ctx, c)
+	if !ok {
+		return
+	}
+
+	if changes.Enderecos != nil {
+		if campos := manterIDsEnderecos(client.Enderecos, *changes.Enderecos); len(campos) > 0 {
+			responderCamposInvalidos(c, campos)
+			return
+		}
+	}
+
+	client, err := h.clients.Update(ctx, client.ID, changes)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar perfil"})
+		return
+	}
+
+	c.JSON(http.StatusOK, client.View())
+}
+
+// manterIDsEnderecos dá um ID aos endereços novos e confere que os enviados
+// com _id são endereços já salvos pelo cliente, cada um uma vez só.
+func manterIDsEnderecos(salvos, enderecos []models.EnderecoSalvo) map[string]string {
+	existentes := map[bson.ObjectID]bool{}
+	for _, salvo := range salvos {
+		existentes[salvo.ID] = true
+	}
+
+	campos := map[string]string{}
+	usados := map[bson.ObjectID]bool{}
+	for i := range enderecos {
+		id := enderecos[i].ID
+		switch {
+		case id.IsZero():
+			enderecos[i].ID = bson.NewObjectID()
+		case !existentes[id]:
+			campos[fmt.Sprintf("enderecos[%d]._id", i)] = "endereço não encontrado"
+		case usados[id]:
+			campos[fmt.Sprintf("enderecos[%d]._id", i)] = "endereço repetido"
+		}
+		usados[id] = true
+	}
+	return campos
+}
+
+// ChangePassword troca a senha de quem está logado. As outras sessões são
+// encerradas e a atual recebe tokens novos.
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var request models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, ok := h.clienteAutenticado(ctx, c)
+	if !ok {
+		return
+	}
+
+	// A senha atual é uma credencial como no login e tem o mesmo limite
+	if !limitar(c, h.loginPorEmail, client.Email) {
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(client.Senha), []byte(request.SenhaAtual)) != nil {
+		responderCamposInvalidos(c, map[string]string{"senha_atual": "senha incorreta"})
+		return
+	}
+	if err := h.loginPorEmail.Reset(ctx, client.Email); err != nil {
+		log.Printf("falha ao zerar tentativas de login: %v", err)
+	}
+
+	if err := models.ValidarSenha(request.NovaSenha); err != nil {
+		responderCamposInvalidos(c, map[string]string{"nova_senha": err.Error()})
+		return
+	}
+	if request.NovaSenha == request.SenhaAtual {
+		responderCamposInvalidos(c, map[string]string{"nova_senha": "deve ser diferente da senha atual"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NovaSenha), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error hashing password"})
+		return
+	}
+
+	senha := string(hashedPassword)
+	if _, err := h.clients.Update(ctx, client.ID, repository.ClientChanges{Senha: &senha}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := h.revogarRefreshTokens(ctx, client.Email); err != nil {
+		log.Printf("falha ao revogar sessões de %s: %v", client.Email, err)
+	}
+
+	tokens, err := h.emitirTokens(ctx, client, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+	tokens["message"] = "Senha atualizada com sucesso"
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// DeleteAccount apaga a conta do cliente. As locações continuam existindo
+// para o histórico da empresa, mas sem os dados pessoais.
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	var request models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responderBind(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, ok := h.clienteAutenticado(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !limitar(c, h.loginPorEmail, client.Email) {
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(client.Senha), []byte(request.Senha)) != nil {
+		responderCamposInvalidos(c, map[string]string{"senha": "senha incorreta"})
+		return
+	}
+
+	// Locações em andamento ainda precisam do contato e do endereço
+	ativas, err := h.locations.CountActiveByEmail(ctx, client.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if ativas > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Há locações em andamento; cancele-as ou aguarde a conclusão",
+			"code":     "ACTIVE_RENTALS",
+			"locacoes": ativas,
+		})
+		return
+	}
+
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		if err := h.clients.Delete(tx.Context(), client.ID); err != nil {
+			return err
+		}
+		tx.OnRollback(func(ctx context.Context) error {
+			return h.clients.Insert(ctx, client)
+		})
+
+		return h.locations.AnonymizeByEmail(tx.Context(), client.Email)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir conta"})
+		return
+	}
+
+	// O que sobra abaixo só expira sozinho; falhas não impedem a exclusão
+	if err := h.revogarRefreshTokens(ctx, client.Email); err != nil {
+		log.Printf("falha ao revogar sessões de %s: %v", client.Email, err)
+	}
+	if err := h.resets.DeleteByEmail(ctx, client.Email); err != nil {
+		log.Printf("falha ao limpar senhasEsquecidas de %s: %v", client.Email, err)
+	}
+	if err := h.verificacoes.DeleteByEmail(ctx, client.Email); err != nil {
+		log.Printf("falha ao limpar verificacoesEmail de %s: %v", client.Email, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conta excluída com sucesso"})
+}
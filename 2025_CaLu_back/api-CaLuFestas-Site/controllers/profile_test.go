@@ -0,0 +1,142 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func enderecoSalvo(apelido string) M {
+	return M{"apelido": apelido, "cep": "01310-100", "logradouro": "Avenida Paulista", "numero": "1000", "bairro": "Bela Vista", "cidade": "São Paulo", "uf": "SP"}
+}
+
+func enderecosDoPerfil(t *testing.T, perfil M) []M {
+	t.Helper()
+	lista, _ := perfil["enderecos"].([]any)
+	enderecos := make([]M, len(lista))
+	for i, e := range lista {
+		enderecos[i] = e.(M)
+	}
+	return enderecos
+}
+
+func TestEditarPerfilMantemIDsDosEnderecos(t *testing.T) {
+	a := novoAmbiente(t)
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	perfil := a.esperar(a.fazer("GET", "/api/me", ana, nil), http.StatusOK).JSON(t)
+	if perfil["nome"] != "Ana" || len(enderecosDoPerfil(t, perfil)) != 0 {
+		t.Fatalf("perfil = %v", perfil)
+	}
+
+	perfil = a.esperar(a.fazer("PATCH", "/api/me", ana, M{"enderecos": []M{enderecoSalvo("Casa")}}), http.StatusOK).JSON(t)
+	casa := enderecosDoPerfil(t, perfil)[0]
+
+	// Reenviar a casa com o _id e acrescentar o trabalho sem _id
+	editada := enderecoSalvo("Casa da Ana")
+	editada["_id"] = casa["_id"]
+	perfil = a.esperar(a.fazer("PATCH", "/api/me", ana, M{"enderecos": []M{editada, enderecoSalvo("Trabalho")}}), http.StatusOK).JSON(t)
+	enderecos := enderecosDoPerfil(t, perfil)
+	if len(enderecos) != 2 || enderecos[0]["_id"] != casa["_id"] || enderecos[0]["apelido"] != "Casa da Ana" {
+		t.Fatalf("endereços = %v, esperado manter o _id %v", enderecos, casa["_id"])
+	}
+	if enderecos[1]["_id"] == casa["_id"] || enderecos[1]["_id"] == bson.NilObjectID.Hex() {
+		t.Fatalf("endereço novo sem ID próprio: %v", enderecos[1])
+	}
+
+	alheio := enderecoSalvo("Outro")
+	alheio["_id"] = bson.NewObjectID().Hex()
+	campos := a.esperar(a.fazer("PATCH", "/api/me", ana, M{"enderecos": []M{alheio}}), http.StatusUnprocessableEntity).JSON(t)["fields"].(M)
+	if campos["enderecos[0]._id"] == nil {
+		t.Fatalf("campos = %v", campos)
+	}
+	a.esperar(a.fazer("PATCH", "/api/me", ana, M{"enderecos": []M{editada, editada}}), http.StatusUnprocessableEntity)
+
+	// Nada mudou com os PATCH recusados
+	perfil = a.esperar(a.fazer("GET", "/api/me", ana, nil), http.StatusOK).JSON(t)
+	if got := enderecosDoPerfil(t, perfil); len(got) != 2 || got[0]["_id"] != casa["_id"] {
+		t.Fatalf("endereços = %v", got)
+	}
+}
+
+func TestTrocarSenha(t *testing.T) {
+	a := novoAmbiente(t)
+	a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	sessao := a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusOK).JSON(t)
+	ana := sessao["token"].(string)
+
+	campos := a.esperar(a.fazer("POST", "/api/me/password", ana, M{"senha_atual": "errada123", "nova_senha": "novaSenha123"}), http.StatusUnprocessableEntity).JSON(t)["fields"].(M)
+	if campos["senha_atual"] == nil {
+		t.Fatalf("campos = %v", campos)
+	}
+	// A senha atual errada não trocou nada nem encerrou a sessão
+	a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusOK)
+
+	nova := a.esperar(a.fazer("POST", "/api/me/password", ana, M{"senha_atual": "senha1234", "nova_senha": "novaSenha123"}), http.StatusOK).JSON(t)
+	if nova["token"] == nil || nova["refresh_token"] == nil {
+		t.Fatalf("troca sem tokens novos: %v", nova)
+	}
+
+	// Os refresh tokens de antes da troca não valem mais; o novo vale
+	a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": sessao["refresh_token"]}), http.StatusUnauthorized)
+	a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": nova["refresh_token"]}), http.StatusOK)
+
+	a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusUnauthorized)
+	a.login("ana@calu.com", "novaSenha123")
+}
+
+func TestExcluirConta(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	id := a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusCreated).JSON(t)["_id"].(string)
+
+	// Sem a senha, ou com a errada, a conta fica
+	a.esperar(a.fazer("DELETE", "/api/me", ana, M{}), http.StatusUnprocessableEntity)
+	a.esperar(a.fazer("DELETE", "/api/me", ana, M{"senha": "errada123"}), http.StatusUnprocessableEntity)
+	a.esperar(a.fazer("DELETE", "/api/me", ana, M{"senha": "senha1234"}), http.StatusConflict)
+
+	a.esperar(a.fazer("PUT", "/api/locations/"+id, ana, M{"estado": models.EstadoCancelada}), http.StatusOK)
+	a.esperar(a.fazer("DELETE", "/api/me", ana, M{"senha": "senha1234"}), http.StatusOK)
+	a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusUnauthorized)
+
+	// A locação continua para a empresa, sem os dados da Ana
+	var locacao M
+	for _, l := range a.esperar(a.fazer("GET", "/api/locations/", admin, nil), http.StatusOK).JSONLista(t) {
+		if l["_id"] == id {
+			locacao = l
+		}
+	}
+	if locacao == nil || locacao["email"] != "" || locacao["nome"] != "Cliente removido" || locacao["endereco"] != "" {
+		t.Fatalf("locação = %v", locacao)
+	}
+
+	// Uma conta nova com o mesmo e-mail não herda o histórico
+	nova := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	pagina := a.esperar(a.fazer("GET", "/api/me/locations", nova, nil), http.StatusOK).JSON(t)
+	if itens, _ := pagina["items"].([]any); len(itens) != 0 {
+		t.Fatalf("locações da conta excluída voltaram: %v", pagina)
+	}
+}
+
+func TestEnderecoSalvoVemDoCEP(t *testing.T) {
+	a := novoAmbiente(t)
+	ana := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	inventado := M{"apelido": "Casa", "cep": "20010-000", "logradouro": "Rua Inventada", "numero": "10", "bairro": "Jardins", "cidade": "São Paulo", "uf": "SP"}
+	perfil := a.esperar(a.fazer("PATCH", "/api/me", ana, M{"enderecos": []M{inventado}}), http.StatusOK).JSON(t)
+	casa := enderecosDoPerfil(t, perfil)[0]
+	if casa["logradouro"] != "Rua Primeiro de Março" || casa["bairro"] != "Centro" || casa["cidade"] != "Rio de Janeiro" || casa["uf"] != "RJ" {
+		t.Fatalf("endereço = %v", casa)
+	}
+
+	inventado["cep"] = "99999-999"
+	corpo := a.esperar(a.fazer("PATCH", "/api/me", ana, M{"enderecos": []M{enderecoSalvo("Trabalho"), inventado}}), http.StatusUnprocessableEntity).JSON(t)
+	if corpo["code"] != "CEP_NOT_FOUND" || corpo["fields"].(M)["enderecos[1].cep"] == nil {
+		t.Fatalf("resposta = %v", corpo)
+	}
+}
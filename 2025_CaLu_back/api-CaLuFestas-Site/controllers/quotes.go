@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	validadeCotacao = 30 * time.Minute
+	audienceCotacao = "quote"
+)
+
+func (h *Handler) CreateQuote(c *gin.Context) {
+	var input models.LocacaoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responderBind(c, err)
+		return
+	}
+	locacao := input.Locacao()
+	if !h.prepararEndereco(c, &locacao) {
+		return
+	}
+
+	inicio, fim, err := models.ParsePeriodo(locacao.DataEntrega, locacao.DataRetirada)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	locacao.Inicio = inicio
+	locacao.Fim = fim
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	orcamento, err := h.precificarLocacao(ctx, &locacao, inicio, fim)
+	if err != nil {
+		responderErro(c, err, "Erro ao calcular o valor da locação")
+		return
+	}
+
+	indisponiveis, err := h.verificarDisponibilidade(ctx, locacao, inicio, fim)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar disponibilidade"})
+		return
+	}
+
+	// Sem estoque não faz sentido garantir o preço, só mostramos a prévia
+	if len(indisponiveis) > 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"disponivel":    false,
+			"indisponiveis": indisponiveis,
+			"orcamento":     orcamento,
+		})
+		return
+	}
+
+	agora := time.Now().UTC()
+	cotacao := models.Cotacao{
+		ID:              bson.NewObjectID(),
+		Items:           locacao.Items,
+		Inicio:          inicio,
+		Fim:             fim,
+		EnderecoEntrega: locacao.EnderecoEntrega,
+		Orcamento:       orcamento,
+		CriadaEm:        agora,
+		ExpiraEm:        agora.Add(validadeCotacao),
+	}
+
+	if err := h.cotacoes.Insert(ctx, cotacao); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar cotação"})
+		return
+	}
+
+	quoteID, err := h.assinarCotacao(cotacao)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao assinar cotação"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"disponivel": true,
+		"quote_id":   quoteID,
+		"expira_em":  cotacao.ExpiraEm,
+		"orcamento":  orcamento,
+	})
+}
+
+// assinarCotacao gera o quote_id entregue ao cliente: um JWT que só aponta
+// para a cotação salva e expira junto com ela.
+func (h *Handler) assinarCotacao(cotacao models.Cotacao) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   cotacao.ID.Hex(),
+		Audience:  jwt.ClaimStrings{audienceCotacao},
+		IssuedAt:  jwt.NewNumericDate(cotacao.CriadaEm),
+		ExpiresAt: jwt.NewNumericDate(cotacao.ExpiraEm),
+	})
+	return token.SignedString([]byte(h.cfg.JWTSecret))
+}
+
+// aplicarCotacao valida o quote_id da locação e, se ele corresponder ao
+// pedido, preenche preços e total com os valores cotados.
+func (h *Handler) aplicarCotacao(ctx context.Context, locacao *models.Locacao) (models.Cotacao, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(locacao.Cotacao, &claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(h.cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(audienceCotacao), jwt.WithExpirationRequired())
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return models.Cotacao{}, &erroHTTP{http.StatusGone, gin.H{"error": "Cotação expirada"}}
+	} else if err != nil {
+		return models.Cotacao{}, &erroHTTP{http.StatusBadRequest, gin.H{"error": "Cotação inválida"}}
+	}
+
+	objID, err := bson.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return models.Cotacao{}, &erroHTTP{http.StatusBadRequest, gin.H{"error": "Cotação inválida"}}
+	}
+
+	cotacao, err := h.cotacoes.FindByID(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return cotacao, &erroHTTP{http.StatusGone, gin.H{"error": "Cotação expirada"}}
+	} else if err != nil {
+		return cotacao, err
+	}
+
+	if cotacao.Usada {
+		return cotacao, &erroHTTP{http.StatusConflict, gin.H{"error": "Cotação já utilizada"}}
+	}
+	if !cotacao.Corresponde(*locacao) {
+		return cotacao, &erroHTTP{http.StatusConflict, gin.H{"error": "Os itens ou o período diferem da cotação"}}
+	}
+
+	cotados := map[bson.ObjectID]models.ItemOrcamento{}
+	for _, item := range cotacao.Orcamento.Itens {
+		cotados[item.ProdutoID] = item
+	}
+	for i, item := range locacao.Items {
+		locacao.Items[i].Nome = cotados[item.ProdutoID].Nome
+		locacao.Items[i].Preco = cotados[item.ProdutoID].PrecoUnitario
+	}
+	if cotacao.Orcamento.Frete != nil {
+		locacao.Frete = cotacao.Orcamento.Frete.Total
+	}
+	locacao.Total = cotacao.Orcamento.Total
+
+	return cotacao, nil
+}
+
+// consumirCotacao marca a cotação como usada para que o mesmo preço não seja
+// aproveitado por mais de uma locação.
+func (h *Handler) consumirCotacao(tx *database.Tx, id bson.ObjectID) error {
+	marcada, err := h.cotacoes.MarkUsed(tx.Context(), id)
+	if err != nil {
+		return err
+	}
+	if !marcada {
+		return &erroHTTP{http.StatusConflict, gin.H{"error": "Cotação já utilizada"}}
+	}
+	tx.OnRollback(func(ctx context.Context) error {
+		return h.cotacoes.UnmarkUsed(ctx, id)
+	})
+	return nil
+}
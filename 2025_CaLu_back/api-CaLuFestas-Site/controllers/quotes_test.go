@@ -0,0 +1,232 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func pedido(produtoID bson.ObjectID, quantidade int) M {
+	return M{
+		"data_entrega":  "2030-01-08",
+		"data_retirada": "2030-01-10",
+		"items":         []M{{"_id": produtoID.Hex(), "quantidade": quantidade}},
+	}
+}
+
+func total(t *testing.T, corpo M) float64 {
+	t.Helper()
+	orcamento, ok := corpo["orcamento"].(M)
+	if !ok {
+		t.Fatalf("resposta sem orçamento: %v", corpo)
+	}
+	return orcamento["total"].(float64)
+}
+
+func TestCotacaoMantemPrecoNaLocacao(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 10, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	cotacao := a.esperar(a.fazer("POST", "/api/quotes/", "", pedido(mesa.ID, 2)), http.StatusCreated).JSON(t)
+	if got := total(t, cotacao); got != 80 {
+		t.Fatalf("total cotado = %v, esperado 80 (2 mesas x 2 diárias x 20)", got)
+	}
+
+	// O preço muda depois da cotação; a locação com o quote_id mantém o cotado
+	a.esperar(a.fazer("POST", "/api/privatePricingRules/", admin, M{
+		"nome":   "Mesa mais cara",
+		"tipo":   models.RegraDiaria,
+		"escopo": M{"produto_id": mesa.ID.Hex()},
+		"preco":  50,
+		"ativa":  true,
+	}), http.StatusCreated)
+
+	comCotacao := pedido(mesa.ID, 2)
+	comCotacao["quote_id"] = cotacao["quote_id"]
+	locacao := a.esperar(a.fazer("POST", "/api/locations/", cliente, comCotacao), http.StatusCreated).JSON(t)
+	if got := total(t, locacao); got != 80 {
+		t.Fatalf("total da locação = %v, esperado o cotado (80)", got)
+	}
+
+	// A mesma cotação não vale para uma segunda locação
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, comCotacao), http.StatusConflict)
+
+	// Sem cotação vale a regra nova
+	locacao = a.esperar(a.fazer("POST", "/api/locations/", cliente, pedido(mesa.ID, 1)), http.StatusCreated).JSON(t)
+	if got := total(t, locacao); got != 100 {
+		t.Fatalf("total sem cotação = %v, esperado 100 (1 mesa x 2 diárias x 50)", got)
+	}
+}
+
+func TestCotacaoNaoServeParaOutroPedido(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 10, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	cotacao := a.esperar(a.fazer("POST", "/api/quotes/", "", pedido(mesa.ID, 2)), http.StatusCreated).JSON(t)
+
+	outro := pedido(mesa.ID, 3)
+	outro["quote_id"] = cotacao["quote_id"]
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, outro), http.StatusConflict)
+
+	invalida := pedido(mesa.ID, 2)
+	invalida["quote_id"] = "nao-e-um-token"
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, invalida), http.StatusBadRequest)
+}
+
+func TestQuoteIDNaoServeComoAccessToken(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 10, Preco: 20}
+	a := novoAmbiente(t, mesa)
+
+	cotacao := a.esperar(a.fazer("POST", "/api/quotes/", "", pedido(mesa.ID, 2)), http.StatusCreated).JSON(t)
+	a.esperar(a.fazer("GET", "/api/me", cotacao["quote_id"].(string), nil), http.StatusUnauthorized)
+}
+
+func TestCotacaoSemEstoqueNaoGeraQuoteID(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 1, Preco: 20}
+	a := novoAmbiente(t, mesa)
+
+	corpo := a.esperar(a.fazer("POST", "/api/quotes/", "", pedido(mesa.ID, 2)), http.StatusOK).JSON(t)
+	if corpo["disponivel"] != false || corpo["quote_id"] != nil {
+		t.Fatalf("esperada prévia sem quote_id: %v", corpo)
+	}
+}
+
+func TestFretePorZonaDeEntrega(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 10, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+
+	a.esperar(a.fazer("POST", "/api/privateDeliveryZones/", admin, M{
+		"nome":          "Centro",
+		"faixas_cep":    []M{{"inicio": "01000-000", "fim": "01999-999"}},
+		"taxa_entrega":  30,
+		"taxa_retirada": 20,
+		"ativa":         true,
+	}), http.StatusCreated)
+
+	// Com zonas ativas o endereço passa a ser obrigatório
+	a.esperar(a.fazer("POST", "/api/quotes/", "", pedido(mesa.ID, 1)), http.StatusUnprocessableEntity)
+
+	endereco := M{"cep": "01310-100", "logradouro": "Avenida Paulista", "numero": "1000", "bairro": "Bela Vista", "cidade": "São Paulo", "uf": "SP"}
+	comEndereco := pedido(mesa.ID, 1)
+	comEndereco["endereco_entrega"] = endereco
+	cotacao := a.esperar(a.fazer("POST", "/api/quotes/", "", comEndereco), http.StatusCreated).JSON(t)
+	if got := total(t, cotacao); got != 90 {
+		t.Fatalf("total = %v, esperado 90 (40 de diárias + 50 de frete)", got)
+	}
+
+	endereco["cep"] = "20040-020"
+	a.esperar(a.fazer("POST", "/api/quotes/", "", comEndereco), http.StatusUnprocessableEntity)
+}
+
+// zonasDeSaoPaulo cadastra uma zona barata pelo bairro Centro e outra, mais
+// cara, pelas faixas de CEP do centro expandido.
+func zonasDeSaoPaulo(a *ambiente, admin string) {
+	a.esperar(a.fazer("POST", "/api/privateDeliveryZones/", admin, M{
+		"nome":          "Centro",
+		"bairros":       []string{"Centro"},
+		"cidade":        "São Paulo",
+		"uf":            "SP",
+		"taxa_entrega":  10,
+		"taxa_retirada": 10,
+		"prioridade":    10,
+		"ativa":         true,
+	}), http.StatusCreated)
+	a.esperar(a.fazer("POST", "/api/privateDeliveryZones/", admin, M{
+		"nome":          "Capital",
+		"faixas_cep":    []M{{"inicio": "01000-000", "fim": "01999-999"}},
+		"taxa_entrega":  30,
+		"taxa_retirada": 20,
+		"ativa":         true,
+	}), http.StatusCreated)
+}
+
+func TestFreteIgnoraBairroDigitado(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 10, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	zonasDeSaoPaulo(a, admin)
+	a.esperar(a.fazer("POST", "/api/privateDeliveryZones/", admin, M{
+		"nome":          "Águas de Lindóia",
+		"bairros":       []string{"Centro"},
+		"cidade":        "Águas de Lindóia",
+		"uf":            "SP",
+		"taxa_entrega":  5,
+		"taxa_retirada": 5,
+		"ativa":         true,
+	}), http.StatusCreated)
+
+	// O CEP é da Bela Vista: o bairro digitado não leva à zona mais barata
+	comEndereco := pedido(mesa.ID, 1)
+	comEndereco["endereco_entrega"] = M{"cep": "01310-100", "logradouro": "Avenida Paulista", "numero": "1000", "bairro": "Centro", "cidade": "São Paulo", "uf": "SP"}
+	cotacao := a.esperar(a.fazer("POST", "/api/quotes/", "", comEndereco), http.StatusCreated).JSON(t)
+	if got := total(t, cotacao); got != 90 {
+		t.Fatalf("total = %v, esperado 90 (40 de diárias + 50 da zona Capital)", got)
+	}
+
+	// CEP único da cidade não traz bairro; o digitado também não vale
+	comEndereco["endereco_entrega"] = M{"cep": "13940-000", "logradouro": "Rua Pernambuco", "numero": "10", "bairro": "Centro", "cidade": "Águas de Lindóia", "uf": "SP"}
+	erro := a.esperar(a.fazer("POST", "/api/quotes/", "", comEndereco), http.StatusUnprocessableEntity).JSON(t)
+	if erro["code"] != "OUT_OF_DELIVERY_AREA" {
+		t.Fatalf("resposta = %v, esperado OUT_OF_DELIVERY_AREA", erro)
+	}
+}
+
+func TestFreteForaDeTodasAsZonas(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 10, Preco: 20}
+	a := novoAmbiente(t, mesa)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	zonasDeSaoPaulo(a, admin)
+
+	// Centro do Rio, ainda que o cliente diga que é São Paulo
+	comEndereco := pedido(mesa.ID, 1)
+	comEndereco["endereco_entrega"] = M{"cep": "20010-000", "logradouro": "Rua Primeiro de Março", "numero": "1", "bairro": "Centro", "cidade": "São Paulo", "uf": "SP"}
+	erro := a.esperar(a.fazer("POST", "/api/quotes/", "", comEndereco), http.StatusUnprocessableEntity).JSON(t)
+	if erro["code"] != "OUT_OF_DELIVERY_AREA" {
+		t.Fatalf("resposta = %v, esperado OUT_OF_DELIVERY_AREA", erro)
+	}
+
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+	a.esperar(a.fazer("POST", "/api/locations/", cliente, comEndereco), http.StatusUnprocessableEntity)
+}
+
+func TestRegrasEZonasExigemAdmin(t *testing.T) {
+	a := novoAmbiente(t)
+	cliente := a.cadastrar("Ana", "ana@calu.com", "senha1234")
+
+	a.esperar(a.fazer("GET", "/api/privatePricingRules/", cliente, nil), http.StatusForbidden)
+	a.esperar(a.fazer("GET", "/api/privateDeliveryZones/", cliente, nil), http.StatusForbidden)
+	a.esperar(a.fazer("GET", "/api/privatePricingRules/", "", nil), http.StatusUnauthorized)
+}
+
+func TestCRUDRegrasDePreco(t *testing.T) {
+	a := novoAmbiente(t)
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+
+	regra := M{"nome": "Fim de semana", "tipo": models.RegraFimDeSemana, "percentual": 20, "ativa": true}
+	criada := a.esperar(a.fazer("POST", "/api/privatePricingRules/", admin, regra), http.StatusCreated).JSON(t)
+	id := criada["_id"].(string)
+
+	regra["percentual"] = 30
+	a.esperar(a.fazer("PUT", "/api/privatePricingRules/"+id, admin, regra), http.StatusOK)
+	a.esperar(a.fazer("PUT", "/api/privatePricingRules/"+bson.NewObjectID().Hex(), admin, regra), http.StatusNotFound)
+
+	a.esperar(a.fazer("DELETE", "/api/privatePricingRules/"+id, admin, nil), http.StatusOK)
+	a.esperar(a.fazer("DELETE", "/api/privatePricingRules/"+id, admin, nil), http.StatusNotFound)
+}
+
+func TestConsultaCEP(t *testing.T) {
+	a := novoAmbiente(t)
+
+	endereco := a.esperar(a.fazer("GET", "/api/cep/01310-100", "", nil), http.StatusOK).JSON(t)
+	if endereco["logradouro"] != "Avenida Paulista" || endereco["cidade"] != "São Paulo" {
+		t.Fatalf("endereço = %v", endereco)
+	}
+	a.esperar(a.fazer("GET", "/api/cep/99999999", "", nil), http.StatusNotFound)
+	a.esperar(a.fazer("GET", "/api/cep/abc", "", nil), http.StatusBadRequest)
+}
@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"log"
+	"time"
+
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxTentativasOTP = 5
+	bloqueioOTP      = 15 * time.Minute
+)
+
+// limitar registra uma tentativa para key e, se o limite tiver sido
+// excedido, responde 429 e devolve false.
+func limitar(c *gin.Context, limiter *ratelimit.Limiter, key string) bool {
+	ok, retryAfter, err := limiter.Allow(c.Request.Context(), key)
+	if err != nil {
+		log.Printf("rate limit indisponível: %v", err)
+	}
+	if !ok {
+		middle.TooManyRequests(c, retryAfter)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TestRespostasNaoExpoemSenha percorre as rotas que devolvem dados de
+// clientes e confere que nenhuma resposta traz o campo senha ou o hash.
+func TestRespostasNaoExpoemSenha(t *testing.T) {
+	mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 3, Preco: 20}
+	a := novoAmbiente(t, mesa)
+
+	var respostas []resposta
+	guardar := func(r resposta) resposta {
+		respostas = append(respostas, r)
+		return r
+	}
+
+	guardar(a.esperar(a.fazer("POST", "/api/clients/", "", M{"nome": "Ana", "email": "ana@calu.com", "senha": "senha1234"}), http.StatusCreated))
+	guardar(a.esperar(a.fazer("POST", "/api/clients/verifyEmail", "", M{"email": "ana@calu.com", "otp_code": a.email.ultimo("ana@calu.com")}), http.StatusOK))
+	sessao := guardar(a.esperar(a.fazer("POST", "/api/clients/login", "", M{"email": "ana@calu.com", "senha": "senha1234"}), http.StatusOK)).JSON(t)
+	ana := sessao["token"].(string)
+	guardar(a.esperar(a.fazer("POST", "/api/clients/refresh", "", M{"refresh_token": sessao["refresh_token"]}), http.StatusOK))
+
+	admin := a.cadastrarAdmin("Admin", "admin@calu.com", "senha1234")
+	conta, err := a.clients.FindByEmail(context.Background(), "ana@calu.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := conta.ID.Hex()
+
+	guardar(a.esperar(a.fazer("POST", "/api/locations/", ana, pedido(mesa.ID, 1)), http.StatusCreated))
+
+	rotas := []struct {
+		method, path, token string
+		body                any
+	}{
+		{"GET", "/api/me", ana, nil},
+		{"PATCH", "/api/me", ana, M{"nome": "Ana Maria"}},
+		{"GET", "/api/me/locations", ana, nil},
+		{"GET", "/api/privateClients/me", ana, nil},
+		{"POST", "/api/locations/cliente", ana, nil},
+		{"GET", "/api/privateClients/", admin, nil},
+		{"GET", "/api/privateClients/" + id, admin, nil},
+		{"PATCH", "/api/privateClients/" + id + "/cargo", admin, M{"cargo": models.CargoUser}},
+		{"POST", "/api/privateClients/" + id + "/deactivate", admin, nil},
+		{"POST", "/api/privateClients/" + id + "/activate", admin, nil},
+		{"GET", "/api/locations/", admin, nil},
+		{"POST", "/api/locations/cliente", admin, M{"email": "ana@calu.com"}},
+	}
+	for _, rota := range rotas {
+		guardar(a.esperar(a.fazer(rota.method, rota.path, rota.token, rota.body), http.StatusOK))
+	}
+
+	comCliente := 0
+	for i, r := range respostas {
+		corpo := string(r.Body)
+		if strings.Contains(corpo, `"senha"`) || strings.Contains(corpo, "$2a$") {
+			t.Errorf("resposta %d expõe a senha: %s", i, corpo)
+		}
+		if strings.Contains(corpo, "ana@calu.com") {
+			comCliente++
+		}
+	}
+	// Garante que o teste passou por respostas que trazem a conta
+	if comCliente < 8 {
+		t.Fatalf("só %d respostas trazem a conta da Ana", comCliente)
+	}
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/tokenutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// emitirTokens gera um access token curto e um refresh token novo. familia
+// vazia inicia uma nova cadeia de rotação (login).
+func (h *Handler) emitirTokens(ctx context.Context, client models.Client, familia string) (gin.H, error) {
+	accessToken, claims, err := tokenutil.GenerateAccessToken(client.ID.Hex(), client.Nome, client.Email, client.Cargo, []byte(h.cfg.JWTSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := tokenutil.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	agora := time.Now().UTC()
+	entry := models.RefreshToken{
+		ID:       bson.NewObjectID(),
+		Email:    client.Email,
+		Hash:     hash,
+		Familia:  familia,
+		CriadoEm: agora,
+		ExpiraEm: agora.Add(tokenutil.RefreshTokenTTL),
+	}
+	if entry.Familia == "" {
+		entry.Familia = entry.ID.Hex()
+	}
+
+	if err := h.tokens.InsertRefresh(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int(time.Until(claims.ExpiresAt.Time).Seconds()),
+	}, nil
+}
+
+func (h *Handler) revogarFamilia(ctx context.Context, familia string) error {
+	return h.tokens.RevokeFamily(ctx, familia, time.Now().UTC())
+}
+
+// revogarRefreshTokens encerra todas as sessões de um cliente.
+func (h *Handler) revogarRefreshTokens(ctx context.Context, email string) error {
+	return h.tokens.RevokeByEmail(ctx, email, time.Now().UTC())
+}
+
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var request models.RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry, err := h.tokens.FindRefreshByHash(ctx, tokenutil.HashToken(request.RefreshToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if entry.RevogadoEm != nil || time.Now().UTC().After(entry.ExpiraEm) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	var tokens gin.H
+	err = h.tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+		// Marcar como usado só funciona uma vez; se o token já tinha sido
+		// usado, alguém o copiou e a cadeia inteira é revogada
+		usado, err := h.tokens.MarkRefreshUsed(tx.Context(), entry.ID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if !usado {
+			return &erroHTTP{http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"}}
+		}
+		tx.OnRollback(func(ctx context.Context) error {
+			return h.tokens.UnmarkRefreshUsed(ctx, entry.ID)
+		})
+
+		client, err := h.clients.FindByEmail(tx.Context(), entry.Email)
+		if errors.Is(err, repository.ErrNotFound) {
+			return &erroHTTP{http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"}}
+		} else if err != nil {
+			return err
+		}
+		if client.Desativado() {
+			return &erroHTTP{http.StatusForbidden, erroContaDesativada}
+		}
+
+		tokens, err = h.emitirTokens(tx.Context(), client, entry.Familia)
+		return err
+	})
+	if err != nil {
+		if isUnauthorized(err) {
+			if err := h.revogarFamilia(ctx, entry.Familia); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+		}
+		responderErro(c, err, "Error generating token")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revoga a cadeia do refresh token enviado e, se a requisição trouxer
+// o access token, coloca o jti dele na lista de revogados até expirar.
+func (h *Handler) Logout(c *gin.Context) {
+	var request models.RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry, err := h.tokens.FindRefreshByHash(ctx, tokenutil.HashToken(request.RefreshToken))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if err == nil {
+		if err := h.revogarFamilia(ctx, entry.Familia); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+	}
+
+	if accessToken, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		claims, err := tokenutil.ParseAccessToken(accessToken, []byte(h.cfg.JWTSecret))
+		if err == nil && claims.ID != "" {
+			revogado := models.TokenRevogado{JTI: claims.ID, ExpiraEm: claims.ExpiresAt.Time}
+			if err := h.tokens.RevokeAccess(ctx, revogado); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout realizado com sucesso"})
+}
+
+func isUnauthorized(err error) bool {
+	var e *erroHTTP
+	return errors.As(err, &e) && e.status == http.StatusUnauthorized
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Os erros de validação citam o campo pelo nome usado no JSON
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			nome := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if nome == "-" {
+				return ""
+			}
+			if nome == "" {
+				return field.Name
+			}
+			return nome
+		})
+	}
+}
+
+// normalizavel é implementada pelos corpos que precisam ser aparados antes
+// da validação.
+type normalizavel interface {
+	Normalizar()
+}
+
+// lerCorpo faz o mesmo que ShouldBindJSON, mas chama Normalizar entre a
+// decodificação e a validação das tags binding.
+func lerCorpo(c *gin.Context, obj normalizavel) error {
+	if c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+		return err
+	}
+	obj.Normalizar()
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// responderBind traduz o erro de ShouldBindJSON ou lerCorpo: JSON malformado é 400, e
+// campos que não passam na validação viram 422 com o motivo de cada um.
+func responderBind(c *gin.Context, err error) {
+	var validacao validator.ValidationErrors
+	if !errors.As(err, &validacao) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_BODY"})
+		return
+	}
+
+	campos := map[string]string{}
+	for _, fe := range validacao {
+		campos[fe.Field()] = mensagemValidacao(fe)
+	}
+	responderCamposInvalidos(c, campos)
+}
+
+func responderCamposInvalidos(c *gin.Context, campos map[string]string) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":  "Dados inválidos",
+		"code":   "VALIDATION_ERROR",
+		"fields": campos,
+	})
+}
+
+func mensagemValidacao(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "campo obrigatório"
+	case "email":
+		return "e-mail inválido"
+	case "max":
+		return fmt.Sprintf("deve ter no máximo %s caracteres", fe.Param())
+	case "min":
+		return fmt.Sprintf("deve ter no mínimo %s caracteres", fe.Param())
+	}
+	return "valor inválido"
+}
@@ -1,31 +1,56 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"os"
+	"time"
 
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 var DB *mongo.Client
 
-func LoadEnv() error {
-	if err := godotenv.Load(); err != nil {
-		return fmt.Errorf("error loading .env file: %w", err)
-	}
-	return nil
-}
+const tentativasConexao = 8
 
-func ConnectDB() {
-	clientOptions := options.Client().ApplyURI(os.Getenv("MONGO_URI"))
+// ConnectDB conecta e espera o Mongo responder ao ping, tentando de novo com
+// espera crescente (1s, 2s, 4s... até 30s). Na subida via docker-compose o
+// banco costuma ficar pronto depois da API.
+func ConnectDB(uri string) {
+	clientOptions := options.Client().ApplyURI(uri)
 	client, err := mongo.Connect(clientOptions)
 	if err != nil {
 		log.Fatal("Error connecting to the DB: ", err)
 	}
+
+	espera := time.Second
+	for tentativa := 1; ; tentativa++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = client.Ping(ctx, nil)
+		cancel()
+		if err == nil {
+			break
+		}
+		if tentativa == tentativasConexao {
+			log.Fatalf("Error connecting to the DB after %d attempts: %v", tentativa, err)
+		}
+
+		log.Printf("Mongo indisponível (tentativa %d/%d): %v; nova tentativa em %s", tentativa, tentativasConexao, err, espera)
+		time.Sleep(espera)
+		espera = min(espera*2, 30*time.Second)
+	}
 	
 	DB = client
 	fmt.Println("Conectado ao banco")
-}
\ No newline at end of file
+}
+
+// Disconnect fecha as conexões abertas por ConnectDB.
+func Disconnect(ctx context.Context) {
+	if DB == nil {
+		return
+	}
+	if err := DB.Disconnect(ctx); err != nil {
+		log.Println("Error disconnecting from the DB: ", err)
+	}
+}
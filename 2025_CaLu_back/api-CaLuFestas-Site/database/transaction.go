@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Tx representa uma unidade de trabalho aberta por um Transactor.
+type Tx struct {
+	ctx           context.Context
+	transactional bool
+	compensations []func(context.Context) error
+}
+
+// Context deve ser usado em todas as operações da unidade de trabalho para
+// que elas participem da sessão/transação.
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// OnRollback registra como desfazer uma escrita já realizada. Dentro de uma
+// transação o próprio abort descarta as escritas, então o registro só é
+// usado quando não há transação.
+func (tx *Tx) OnRollback(undo func(ctx context.Context) error) {
+	if tx.transactional {
+		return
+	}
+	tx.compensations = append(tx.compensations, undo)
+}
+
+func (tx *Tx) rollback() {
+	for i := len(tx.compensations) - 1; i >= 0; i-- {
+		if err := tx.compensations[i](context.Background()); err != nil {
+			log.Printf("falha ao compensar escrita: %v", err)
+		}
+	}
+}
+
+// Transactor executa fn como uma unidade de trabalho: ou todas as escritas
+// valem, ou nenhuma.
+type Transactor interface {
+	RunInTransaction(ctx context.Context, fn func(tx *Tx) error) error
+}
+
+// Garante que as duas implementações satisfazem Transactor
+var (
+	_ Transactor = (*MongoTransactor)(nil)
+	_ Transactor = Compensating{}
+)
+
+// Compensating roda fn sem transação e, se falhar, executa as compensações
+// registradas com OnRollback em ordem inversa. É o que sobra quando o
+// armazenamento não tem transações, como os repositórios em memória.
+//
+// Sem isolamento não há proteção contra reservas simultâneas: duas locações
+// podem passar pela verificação de disponibilidade antes de qualquer uma ser
+// gravada e, juntas, exceder o estoque.
+type Compensating struct{}
+
+func (Compensating) RunInTransaction(ctx context.Context, fn func(tx *Tx) error) error {
+	tx := &Tx{ctx: ctx}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+// MongoTransactor usa transações multi-documento quando o servidor as
+// suporta e cai para Compensating caso contrário.
+type MongoTransactor struct {
+	client *mongo.Client
+
+	mu         sync.Mutex
+	verificado bool
+	supported  bool
+}
+
+func NewMongoTransactor(client *mongo.Client) *MongoTransactor {
+	return &MongoTransactor{client: client}
+}
+
+// SupportsTransactions verifica se o servidor faz parte de um replica set ou
+// é um mongos; um mongod standalone não aceita transações. Só a resposta do
+// servidor é guardada: se a verificação falhar (banco fora do ar, por
+// exemplo), ela é refeita na próxima unidade de trabalho.
+func (t *MongoTransactor) SupportsTransactions(ctx context.Context) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.verificado {
+		return t.supported, nil
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := t.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, fmt.Errorf("verificar suporte a transações: %w", err)
+	}
+
+	t.verificado = true
+	t.supported = hello.SetName != "" || hello.Msg == "isdbgrid"
+	return t.supported, nil
+}
+
+// RunInTransaction executa fn em uma transação multi-documento. Em servidores
+// sem suporte a transações fn roda sem sessão e, se falhar, as compensações
+// registradas com OnRollback são executadas em ordem inversa.
+//
+// Dentro de uma transação fn pode ser reexecutada pelo driver em erros
+// transitórios, então não deve ter efeitos fora do banco.
+func (t *MongoTransactor) RunInTransaction(ctx context.Context, fn func(tx *Tx) error) error {
+	supported, err := t.SupportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return Compensating{}.RunInTransaction(ctx, fn)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc context.Context) (interface{}, error) {
+		return nil, fn(&Tx{ctx: sc, transactional: true})
+	})
+	return err
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/internal/mongotest"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var errFalhou = errors.New("falhou")
+
+func TestCompensatingDesfazEmOrdemInversa(t *testing.T) {
+	var ordem []int
+	err := Compensating{}.RunInTransaction(context.Background(), func(tx *Tx) error {
+		for i := 1; i <= 3; i++ {
+			tx.OnRollback(func(context.Context) error {
+				ordem = append(ordem, i)
+				return nil
+			})
+		}
+		return errFalhou
+	})
+	if !errors.Is(err, errFalhou) {
+		t.Fatalf("erro = %v, esperado errFalhou", err)
+	}
+	if !reflect.DeepEqual(ordem, []int{3, 2, 1}) {
+		t.Fatalf("compensações em %v, esperado [3 2 1]", ordem)
+	}
+}
+
+func TestMongoTransactorNaoGuardaFalhaNaVerificacao(t *testing.T) {
+	// Porta sem servidor: o hello falha por timeout
+	client, err := mongo.Connect(options.Client().
+		ApplyURI("mongodb://127.0.0.1:1/?directConnection=true").
+		SetServerSelectionTimeout(100 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	tr := NewMongoTransactor(client)
+	chamou := false
+	err = tr.RunInTransaction(context.Background(), func(*Tx) error {
+		chamou = true
+		return nil
+	})
+	if err == nil || chamou {
+		t.Fatalf("esperado erro sem executar fn (err=%v, chamou=%v)", err, chamou)
+	}
+	if tr.verificado {
+		t.Fatal("falha no hello ficou guardada como resposta do servidor")
+	}
+}
+
+func TestMongoTransactorConfirma(t *testing.T) {
+	db := mongotest.Banco(t)
+	mongotest.ExigirReplicaSet(t, db.Client())
+	colecao := db.Collection("itens")
+	ctx := context.Background()
+
+	// Transações não criam coleções em servidores antigos
+	if err := db.CreateCollection(ctx, "itens"); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewMongoTransactor(db.Client())
+	if ok, err := tr.SupportsTransactions(ctx); err != nil || !ok {
+		t.Fatalf("SupportsTransactions = %v (%v) em um replica set", ok, err)
+	}
+	err := tr.RunInTransaction(ctx, func(tx *Tx) error {
+		if !tx.transactional {
+			t.Error("replica set sem transação")
+		}
+		_, err := colecao.InsertMany(tx.Context(), []any{bson.M{"n": 1}, bson.M{"n": 2}})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := colecao.CountDocuments(ctx, bson.M{}); err != nil || n != 2 {
+		t.Fatalf("documentos = %d (%v), esperado 2", n, err)
+	}
+}
+
+func TestMongoTransactorDesfazEscritas(t *testing.T) {
+	db := mongotest.Banco(t)
+	mongotest.ExigirReplicaSet(t, db.Client())
+	colecao := db.Collection("itens")
+	ctx := context.Background()
+
+	if err := db.CreateCollection(ctx, "itens"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := colecao.InsertOne(ctx, bson.M{"_id": "estoque", "n": 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewMongoTransactor(db.Client())
+	compensou := false
+	err := tr.RunInTransaction(ctx, func(tx *Tx) error {
+		// Dentro da transação o abort basta; a compensação não deve rodar
+		tx.OnRollback(func(context.Context) error {
+			compensou = true
+			return nil
+		})
+		if _, err := colecao.UpdateByID(tx.Context(), "estoque", bson.M{"$inc": bson.M{"n": -3}}); err != nil {
+			return err
+		}
+		if _, err := colecao.InsertOne(tx.Context(), bson.M{"n": 1}); err != nil {
+			return err
+		}
+		return errFalhou
+	})
+	if !errors.Is(err, errFalhou) {
+		t.Fatalf("erro = %v, esperado errFalhou", err)
+	}
+	if compensou {
+		t.Fatal("compensação executada dentro de uma transação")
+	}
+
+	var estoque struct {
+		N int `bson:"n"`
+	}
+	if err := colecao.FindOne(ctx, bson.M{"_id": "estoque"}).Decode(&estoque); err != nil {
+		t.Fatal(err)
+	}
+	if estoque.N != 10 {
+		t.Fatalf("estoque = %d, esperado 10 após o rollback", estoque.N)
+	}
+	if n, err := colecao.CountDocuments(ctx, bson.M{}); err != nil || n != 1 {
+		t.Fatalf("documentos = %d (%v), esperado só o estoque", n, err)
+	}
+}
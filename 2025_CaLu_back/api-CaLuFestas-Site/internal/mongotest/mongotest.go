@@ -0,0 +1,81 @@
+// Package mongotest liga os testes de integração a um MongoDB de verdade.
+//
+// Os testes que usam o pacote são pulados quando MONGO_TEST_URI não está
+// definida. Para rodá-los contra o mongo do docker-compose:
+//
+//	MONGO_TEST_URI='mongodb://localhost:27017/?directConnection=true' go test ./...
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const Variavel = "MONGO_TEST_URI"
+
+// Client conecta ao servidor de MONGO_TEST_URI e fecha a conexão no fim do
+// teste.
+func Client(t testing.TB) *mongo.Client {
+	t.Helper()
+
+	uri := os.Getenv(Variavel)
+	if uri == "" {
+		t.Skipf("%s não definida; teste de integração com o MongoDB pulado", Variavel)
+	}
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("conectar ao MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		client.Disconnect(ctx)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatalf("MongoDB em %s não respondeu: %v", uri, err)
+	}
+	return client
+}
+
+// Banco devolve um banco exclusivo do teste, apagado ao final.
+func Banco(t testing.TB) *mongo.Database {
+	t.Helper()
+
+	client := Client(t)
+	db := client.Database(fmt.Sprintf("calufestas_teste_%s", bson.NewObjectID().Hex()))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		db.Drop(ctx)
+	})
+	return db
+}
+
+// ExigirReplicaSet pula o teste quando o servidor é um mongod standalone,
+// que não aceita transações.
+func ExigirReplicaSet(t testing.TB, client *mongo.Client) {
+	t.Helper()
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(context.Background(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		t.Fatalf("hello: %v", err)
+	}
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		t.Skip("MongoDB standalone; o teste exige um replica set")
+	}
+}
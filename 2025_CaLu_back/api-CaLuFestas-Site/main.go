@@ -1,45 +1,132 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/Psnsilvino/CaluFestas-Site-api/config"
 	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
 	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"github.com/Psnsilvino/CaluFestas-Site-api/migrations"
 	"github.com/Psnsilvino/CaluFestas-Site-api/routes"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/cep"
 	"github.com/Psnsilvino/CaluFestas-Site-api/utils/email"
-	"github.com/joho/godotenv"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
 )
 
-func toInt(s string) int {
-	i, err := strconv.Atoi(s)
+// Tempo entre o /readyz começar a falhar e o servidor parar de aceitar
+// conexões; cobre alguns ciclos da sonda de prontidão
+const esperaBalanceador = 5 * time.Second
+
+func main() {
+	// Lê env, .env e config.yaml; sobe só com a configuração completa
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Invalid SMTP_PORT: %v", err)
+		log.Fatal(err)
 	}
-	return i
-}
 
-func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
 	// Connect to MongoDB
-	database.ConnectDB()
-	
-	// Inicializar o sender global
-	sender := email.NewSender(os.Getenv("SMTP_HOST"), toInt(os.Getenv("SMTP_PORT")), os.Getenv("SMTP_EMAIL"), os.Getenv("SMTP_PSW"))
-	controllers.InitEmailSender(sender)
+	database.ConnectDB(cfg.Mongo.URI)
+
+	db := database.DB.Database(cfg.Mongo.DB)
+
+	// As migrações não rodam na subida, mas a API depende delas (o índice
+	// único de clients.email, por exemplo); sem elas, não sobe
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	pendentes, err := migrations.Pendentes(ctx, db)
+	cancel()
+	if err != nil {
+		log.Fatal("Error checking migrations: ", err)
+	}
+	if len(pendentes) > 0 {
+		log.Fatalf("%d migração(ões) pendente(s), a partir da %04d (%s); execute \"./main migrate up\"", len(pendentes), pendentes[0].Versao, pendentes[0].Nome)
+	}
+
+	// Sem transações a API funciona, mas reservas simultâneas podem exceder
+	// o estoque; em produção o Mongo deve rodar como replica set
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	transacoes, err := database.NewMongoTransactor(database.DB).SupportsTransactions(ctx)
+	cancel()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !transacoes {
+		log.Println("AVISO: MongoDB sem suporte a transações; usando compensação manual, e reservas simultâneas podem exceder o estoque")
+	}
+
+	sender := email.NewSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Email, cfg.SMTP.Password)
+
+	// Contadores de tentativas; com várias instâncias da API devem ficar no Mongo
+	var limits ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitStore == "mongo" {
+		limits = ratelimit.NewMongoStore(db.Collection("rateLimits"))
+	}
+
+	var addresses cep.AddressResolver = cep.NewViaCEP(cfg.CEP.ViaCEPURL)
+	if cfg.CEP.Resolver == "offline" {
+		addresses = cep.Fixtures()
+		if cfg.CEP.FixturesFile != "" {
+			if addresses, err = cep.LoadStaticFile(cfg.CEP.FixturesFile); err != nil {
+				log.Fatal("CEP_FIXTURES_FILE: ", err)
+			}
+		}
+	}
+
+	h := controllers.NewMongo(cfg, database.DB, db, sender, addresses, limits)
 
 	// Setup routes
-	r := routes.SetupRouter()
+	r := routes.SetupRouter(cfg, h, limits)
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: r}
+
+	// SIGTERM (docker stop) e Ctrl+C param de aceitar conexões e esperam as
+	// requisições em andamento antes de fechar o banco
+	stop, cancelStop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancelStop()
+
+	erros := make(chan error, 1)
+	go func() {
+		erros <- srv.ListenAndServe()
+	}()
+
+	var falha error
+	select {
+	case err := <-erros:
+		if !errors.Is(err, http.ErrServerClosed) {
+			falha = err
+		}
+	case <-stop.Done():
+		log.Println("Encerrando o servidor...")
+		// O /readyz passa a responder 503 antes de o servidor parar de
+		// aceitar conexões, dando tempo de o balanceador tirar a instância
+		h.Encerrar()
+		if cfg.Env == config.Production {
+			time.Sleep(esperaBalanceador)
+		}
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println("Error shutting down server: ", err)
 	}
-	log.Fatal(r.Run(":" + port))
-}
\ No newline at end of file
+	database.Disconnect(ctx)
+
+	if falha != nil {
+		log.Fatal("Error running server: ", falha)
+	}
+}
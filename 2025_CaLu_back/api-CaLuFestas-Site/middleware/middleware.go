@@ -2,20 +2,20 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
-	"github.com/Psnsilvino/CaluFestas-Site-api/database"
 	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/Psnsilvino/CaluFestas-Site-api/repository"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/tokenutil"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware valida o access token e carrega o cliente dele; "user" e
+// "cargo" no contexto vêm sempre do registro atual do cliente.
+func AuthMiddleware(clients repository.ClientRepository, tokens repository.TokenRepository, secret []byte) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -31,44 +31,52 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := tokenutil.ParseAccessToken(tokenString, secret)
+		if err != nil || claims.ID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// Tokens de sessões encerradas com logout continuam assinados, então
+		// a lista de revogados é consultada a cada requisição
+		revogado, err := tokens.IsAccessRevoked(ctx, claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			c.Abort()
+			return
+		}
+		if revogado {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token revoked"})
 			c.Abort()
 			return
 		}
 
-		mail := claims["email"].(string)
-		carg := claims["cargo"].(string)
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		mail := models.NormalizeEmail(claims.Email)
 
-		var client models.Client
-		err = database.DB.Database(os.Getenv("DB_NAME")).Collection("clients").FindOne(ctx, bson.M{"email": mail}).Decode(&client)
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials o", "1": mail})
+		client, err := clients.FindByEmail(ctx, mail)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			c.Abort()
 			return
 		} else if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			c.Abort()
+			return
+		}
+
+		if client.Status == models.StatusDesativado {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Conta desativada", "code": "ACCOUNT_DISABLED"})
+			c.Abort()
 			return
 		}
 
-		c.Set("user", mail)
-		c.Set("cargo", carg)
+		// O cargo vem do banco, e não do token, para que mudanças de cargo valham na hora
+		c.Set("user", client.Email)
+		c.Set("cargo", client.Cargo)
 
 		c.Next()
 	}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limita as requisições por IP de origem.
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, retryAfter, err := limiter.Allow(c.Request.Context(), c.ClientIP())
+		if err != nil {
+			// Sem o contador é melhor deixar passar do que derrubar o login
+			log.Printf("rate limit indisponível: %v", err)
+		}
+		if !ok {
+			TooManyRequests(c, retryAfter)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TooManyRequests responde 429 com o cabeçalho Retry-After em segundos.
+func TooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Muitas tentativas, tente novamente mais tarde",
+		"retry_after": seconds,
+	})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole libera a rota apenas para os cargos informados. Deve vir depois
+// de AuthMiddleware, que preenche o cargo do usuário autenticado.
+func RequireRole(cargos ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, cargo := range cargos {
+			if HasRole(c, cargo) {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acesso não permitido para o seu cargo"})
+		c.Abort()
+	}
+}
+
+// HasRole permite que handlers compartilhados entre cargos ajustem o
+// comportamento, por exemplo limitando clientes aos próprios dados.
+func HasRole(c *gin.Context, cargo string) bool {
+	return c.GetString("cargo") == cargo
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/config"
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"github.com/Psnsilvino/CaluFestas-Site-api/migrations"
+)
+
+const usoMigrate = "uso: main migrate up|status"
+
+// migrate executa o subcomando "migrate":
+//
+//	./main migrate up      aplica as migrações pendentes
+//	./main migrate status  lista as migrações e quando cada uma foi aplicada
+func migrate(cfg config.Config, args []string) error {
+	if len(args) != 1 || (args[0] != "up" && args[0] != "status") {
+		return errors.New(usoMigrate)
+	}
+
+	database.ConnectDB(cfg.Mongo.URI)
+	db := database.DB.Database(cfg.Mongo.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	defer database.Disconnect(context.Background())
+
+	switch args[0] {
+	case "up":
+		feitas, err := migrations.Up(ctx, db)
+		for _, m := range feitas {
+			fmt.Printf("%04d  %s\n", m.Versao, m.Nome)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migrações aplicadas\n", len(feitas))
+		return nil
+
+	case "status":
+		estados, err := migrations.Status(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, e := range estados {
+			situacao := "pendente"
+			if e.AplicadaEm != nil {
+				situacao = "aplicada em " + e.AplicadaEm.Local().Format(time.DateTime)
+			}
+			fmt.Printf("%04d  %-30s  %s\n", e.Versao, situacao, e.Nome)
+		}
+	}
+	return nil
+}
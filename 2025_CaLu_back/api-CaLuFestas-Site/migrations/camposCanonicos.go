@@ -0,0 +1,126 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var renomear = map[string]bson.M{
+	"produtos": {
+		"quantidadeemlocacao": "quantidade_em_locacao",
+		"arquivadoem":         "arquivado_em",
+	},
+	"senhasEsquecidas": {
+		"otpcode":        "otp_code",
+		"expiresat":      "expires_at",
+		"isverified":     "is_verified",
+		"createdat":      "created_at",
+		"resettokenhash": "reset_token_hash",
+		"usedat":         "used_at",
+		"lockeduntil":    "locked_until",
+	},
+}
+
+// camposCanonicos leva os documentos gravados antes das tags bson explícitas
+// para os nomes atuais:
+//
+//   - em "clients", "produtos" e "senhasEsquecidas" o ID sai do campo "id" e
+//     passa a ser o _id do documento. Como o valor é mantido, as locações
+//     que referenciam produtos continuam válidas;
+//   - os campos gravados em minúsculas corridas ganham snake_case
+//     (isverified -> is_verified, quantidadeemlocacao -> quantidade_em_locacao...).
+func camposCanonicos(ctx context.Context, db *mongo.Database) error {
+	tx := database.NewMongoTransactor(db.Client())
+
+	for _, collection := range []string{"clients", "produtos", "senhasEsquecidas"} {
+		if err := moverIDs(ctx, tx, db.Collection(collection)); err != nil {
+			return err
+		}
+
+		campos, ok := renomear[collection]
+		if !ok {
+			continue
+		}
+		if _, err := db.Collection(collection).UpdateMany(ctx, comAlgumCampo(campos), bson.M{"$rename": campos}); err != nil {
+			return err
+		}
+	}
+
+	// O índice TTL antigo olhava para "expiresat", que deixou de existir; o
+	// novo, em "expires_at", é criado pela migração de índices
+	err := db.Collection("senhasEsquecidas").Indexes().DropOne(ctx, "expiresat_1")
+	if err != nil && !indiceInexistente(err) {
+		return err
+	}
+	return nil
+}
+
+// moverIDs troca cada documento que ainda tem o campo "id" por uma cópia cujo
+// _id é esse valor. O _id não pode ser alterado, então a troca é feita
+// apagando o original e inserindo a cópia na mesma unidade de trabalho; a
+// remoção vem antes para não violar o índice único de clients.email.
+//
+// Um "id" zerado ou nulo nunca foi preenchido (todo pedido antigo de
+// "senhasEsquecidas" tem ObjectID(000...0)); usá-lo como _id faria os
+// documentos colidirem, então o campo é só removido e o _id atual fica.
+func moverIDs(ctx context.Context, tx database.Transactor, collection *mongo.Collection) error {
+	vazio := bson.M{"id": bson.M{"$exists": true, "$in": bson.A{bson.ObjectID{}, nil, ""}}}
+	if _, err := collection.UpdateMany(ctx, vazio, bson.M{"$unset": bson.M{"id": ""}}); err != nil {
+		return err
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{"id": bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var original bson.M
+		if err := cursor.Decode(&original); err != nil {
+			return err
+		}
+
+		copia := bson.M{}
+		for campo, valor := range original {
+			copia[campo] = valor
+		}
+		copia["_id"] = original["id"]
+		delete(copia, "id")
+
+		err := tx.RunInTransaction(ctx, func(tx *database.Tx) error {
+			if _, err := collection.DeleteOne(tx.Context(), bson.M{"_id": original["_id"]}); err != nil {
+				return err
+			}
+			tx.OnRollback(func(ctx context.Context) error {
+				_, err := collection.InsertOne(ctx, original)
+				return err
+			})
+
+			_, err := collection.InsertOne(tx.Context(), copia)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func comAlgumCampo(campos bson.M) bson.M {
+	var filtros []bson.M
+	for antigo := range campos {
+		filtros = append(filtros, bson.M{antigo: bson.M{"$exists": true}})
+	}
+	return bson.M{"$or": filtros}
+}
+
+func indiceInexistente(err error) bool {
+	var cmdErr mongo.CommandError
+	// 26: NamespaceNotFound, 27: IndexNotFound
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 26 || cmdErr.Code == 27)
+}
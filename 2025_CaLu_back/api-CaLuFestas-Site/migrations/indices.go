@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// criarIndices cria os índices de que a API depende. CreateMany não faz nada
+// para índices que já existem com a mesma definição. Uma coleção que falhar
+// não impede as demais; os erros são devolvidos juntos no fim.
+func criarIndices(ctx context.Context, db *mongo.Database) error {
+	indexes := []struct {
+		colecao string
+		modelos []mongo.IndexModel
+	}{
+		// Falha se já houver e-mails duplicados; eles precisam ser resolvidos
+		// antes
+		{"clients", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		}},
+		// Consultas de disponibilidade (produto + período) e a listagem das
+		// locações do cliente
+		{"locations", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "items._id", Value: 1}, {Key: "inicio", Value: 1}, {Key: "fim", Value: 1}}},
+			{Keys: bson.D{{Key: "email", Value: 1}, {Key: "_id", Value: -1}}},
+		}},
+		{"cotacoes", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "expira_em", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+		{"senhasEsquecidas", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "email", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+		{"rateLimits", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "key", Value: 1}}},
+			{Keys: bson.D{{Key: "expira_em", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+		{"verificacoesEmail", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "email", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+		{"refreshTokens", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "familia", Value: 1}}},
+			{Keys: bson.D{{Key: "expira_em", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+		{"tokensRevogados", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "jti", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "expira_em", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		}},
+	}
+
+	var erros []error
+	for _, c := range indexes {
+		if _, err := db.Collection(c.colecao).Indexes().CreateMany(ctx, c.modelos); err != nil {
+			erros = append(erros, fmt.Errorf("%s: %w", c.colecao, err))
+		}
+	}
+	return errors.Join(erros...)
+}
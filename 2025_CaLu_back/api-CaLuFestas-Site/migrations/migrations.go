@@ -0,0 +1,125 @@
+// Package migrations evolui os dados do Mongo (índices, renomeação de
+// campos, backfills) por meio de migrações versionadas. As aplicadas ficam
+// registradas na coleção "schema_migrations", uma por documento, com a versão
+// como _id.
+//
+// Toda migração deve poder ser executada de novo sem efeito: se o processo
+// cair depois de aplicar e antes de registrar, ela roda outra vez.
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const colecao = "schema_migrations"
+
+type Migration struct {
+	Versao int
+	Nome   string
+	Up     func(ctx context.Context, db *mongo.Database) error
+}
+
+// Todas lista as migrações em ordem de versão. Novas migrações entram no fim
+// com a próxima versão; as já publicadas não devem ser alteradas.
+var Todas = []Migration{
+	{1, "nomes canônicos dos campos bson", camposCanonicos},
+	{2, "_id do produto nos itens das locações", preencherItens},
+	{3, "e-mails aparados e em minúsculas", normalizarEmails},
+	{4, "índices", criarIndices},
+}
+
+type registro struct {
+	Versao     int       `bson:"_id"`
+	Nome       string    `bson:"nome"`
+	AplicadaEm time.Time `bson:"aplicada_em"`
+}
+
+// Estado é a situação de uma migração; AplicadaEm é nil enquanto pendente.
+type Estado struct {
+	Migration
+	AplicadaEm *time.Time
+}
+
+// Status devolve todas as migrações conhecidas com a data em que cada uma foi
+// aplicada.
+func Status(ctx context.Context, db *mongo.Database) ([]Estado, error) {
+	aplicadas, err := aplicadas(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	estados := make([]Estado, len(Todas))
+	for i, m := range Todas {
+		estados[i] = Estado{Migration: m}
+		if r, ok := aplicadas[m.Versao]; ok {
+			estados[i].AplicadaEm = &r.AplicadaEm
+		}
+	}
+	return estados, nil
+}
+
+// Pendentes devolve as migrações ainda não aplicadas, em ordem.
+func Pendentes(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	estados, err := Status(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pendentes []Migration
+	for _, e := range estados {
+		if e.AplicadaEm == nil {
+			pendentes = append(pendentes, e.Migration)
+		}
+	}
+	return pendentes, nil
+}
+
+// Up aplica as migrações pendentes em ordem e para na primeira que falhar,
+// devolvendo as que foram aplicadas até ali.
+func Up(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	pendentes, err := Pendentes(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var feitas []Migration
+	for _, m := range pendentes {
+		if err := m.Up(ctx, db); err != nil {
+			return feitas, fmt.Errorf("migração %04d (%s): %w", m.Versao, m.Nome, err)
+		}
+
+		r := registro{Versao: m.Versao, Nome: m.Nome, AplicadaEm: time.Now().UTC()}
+		// Outra instância pode ter aplicado a mesma migração ao mesmo tempo;
+		// como elas são idempotentes, basta o primeiro registro
+		if _, err := db.Collection(colecao).InsertOne(ctx, r); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return feitas, fmt.Errorf("registrar migração %04d: %w", m.Versao, err)
+		}
+		feitas = append(feitas, m)
+	}
+	return feitas, nil
+}
+
+func aplicadas(ctx context.Context, db *mongo.Database) (map[int]registro, error) {
+	cursor, err := db.Collection(colecao).Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var registros []registro
+	if err := cursor.All(ctx, &registros); err != nil {
+		return nil, err
+	}
+
+	porVersao := make(map[int]registro, len(registros))
+	for _, r := range registros {
+		porVersao[r.Versao] = r
+	}
+	return porVersao, nil
+}
@@ -0,0 +1,128 @@
+package migrations
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/internal/mongotest"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestTodasEmOrdemDeVersao(t *testing.T) {
+	for i, m := range Todas {
+		if m.Versao != i+1 {
+			t.Fatalf("migração %q tem versão %d, esperado %d", m.Nome, m.Versao, i+1)
+		}
+	}
+}
+
+func TestUpAplicaPendentesUmaVez(t *testing.T) {
+	db := mongotest.Banco(t)
+	ctx := context.Background()
+
+	feitas, err := Up(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feitas) != len(Todas) {
+		t.Fatalf("%d migrações aplicadas, esperado %d", len(feitas), len(Todas))
+	}
+
+	feitas, err = Up(ctx, db)
+	if err != nil || len(feitas) != 0 {
+		t.Fatalf("segunda execução aplicou %d migrações (erro %v)", len(feitas), err)
+	}
+	if pendentes, err := Pendentes(ctx, db); err != nil || len(pendentes) != 0 {
+		t.Fatalf("pendentes = %v (erro %v)", pendentes, err)
+	}
+}
+
+func TestCamposCanonicosIgnoraIDZerado(t *testing.T) {
+	db := mongotest.Banco(t)
+	ctx := context.Background()
+
+	// Todo pedido antigo de redefinição foi gravado com o mesmo id zerado
+	senhas := db.Collection("senhasEsquecidas")
+	_, err := senhas.InsertMany(ctx, []any{
+		bson.M{"id": bson.ObjectID{}, "email": "ana@calu.com", "otpcode": "123456"},
+		bson.M{"id": bson.ObjectID{}, "email": "bia@calu.com", "otpcode": "654321"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idAna := bson.NewObjectID()
+	if _, err := db.Collection("clients").InsertOne(ctx, bson.M{"id": idAna, "email": "ana@calu.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := camposCanonicos(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := senhas.CountDocuments(ctx, bson.M{"id": bson.M{"$exists": true}}); err != nil || n != 0 {
+		t.Fatalf("%d pedidos ainda com id (erro %v)", n, err)
+	}
+	if n, err := senhas.CountDocuments(ctx, bson.M{"otp_code": bson.M{"$exists": true}}); err != nil || n != 2 {
+		t.Fatalf("%d pedidos com otp_code, esperado 2 (erro %v)", n, err)
+	}
+
+	var ana bson.M
+	if err := db.Collection("clients").FindOne(ctx, bson.M{"_id": idAna}).Decode(&ana); err != nil {
+		t.Fatalf("cliente sem o id antigo como _id: %v", err)
+	}
+	if _, ok := ana["id"]; ok {
+		t.Fatal("campo id continuou no cliente")
+	}
+}
+
+func TestCriarIndicesSegueAposFalha(t *testing.T) {
+	db := mongotest.Banco(t)
+	ctx := context.Background()
+
+	_, err := db.Collection("clients").InsertMany(ctx, []any{
+		bson.M{"email": "ana@calu.com"},
+		bson.M{"email": "ana@calu.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = criarIndices(ctx, db)
+	if err == nil || !strings.Contains(err.Error(), "clients") {
+		t.Fatalf("erro = %v, esperada a falha do índice único de clients", err)
+	}
+
+	// As coleções seguintes recebem os índices mesmo assim
+	for _, c := range []struct{ colecao, indice string }{
+		{"locations", "email_1__id_-1"},
+		{"refreshTokens", "hash_1"},
+		{"tokensRevogados", "jti_1"},
+	} {
+		if !temIndice(t, db.Collection(c.colecao), c.indice) {
+			t.Errorf("%s sem o índice %s", c.colecao, c.indice)
+		}
+	}
+}
+
+func temIndice(t *testing.T, colecao *mongo.Collection, nome string) bool {
+	t.Helper()
+
+	cursor, err := colecao.Indexes().List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indices []struct {
+		Nome string `bson:"name"`
+	}
+	if err := cursor.All(context.Background(), &indices); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range indices {
+		if i.Nome == nome {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Coleções em que o e-mail é chave de consulta, além de "clients"
+var colecoesComEmail = []string{"locations", "senhasEsquecidas", "verificacoesEmail", "refreshTokens"}
+
+// emailNormalizado é a expressão de agregação equivalente a
+// models.NormalizeEmail.
+var emailNormalizado = bson.D{{Key: "$toLower", Value: bson.D{{Key: "$trim", Value: bson.D{{Key: "input", Value: "$email"}}}}}}
+
+// normalizarEmails grava os e-mails já aparados e em minúsculas, como a API
+// passou a consultá-los. Se duas contas ficarem com o mesmo e-mail a migração
+// falha sem alterar nada e lista as colisões, que precisam ser resolvidas à
+// mão antes do índice único de clients.email.
+func normalizarEmails(ctx context.Context, db *mongo.Database) error {
+	colisoes, err := colisoesDeEmail(ctx, db.Collection("clients"))
+	if err != nil {
+		return err
+	}
+	if len(colisoes) > 0 {
+		return fmt.Errorf("contas com o mesmo e-mail após a normalização, resolva antes de migrar:\n  %s", strings.Join(colisoes, "\n  "))
+	}
+
+	for _, nome := range append([]string{"clients"}, colecoesComEmail...) {
+		result, err := db.Collection(nome).UpdateMany(ctx,
+			bson.M{"email": bson.M{"$type": "string"}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "email", Value: emailNormalizado}}}}},
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", nome, err)
+		}
+		if result.ModifiedCount > 0 {
+			log.Printf("%s: %d e-mail(s) normalizado(s)", nome, result.ModifiedCount)
+		}
+	}
+	return nil
+}
+
+// colisoesDeEmail descreve cada e-mail normalizado usado por mais de uma
+// conta, com os _id e e-mails originais envolvidos.
+func colisoesDeEmail(ctx context.Context, clients *mongo.Collection) ([]string, error) {
+	cursor, err := clients.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"email": bson.M{"$type": "string"}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: emailNormalizado},
+			{Key: "contas", Value: bson.D{{Key: "$push", Value: bson.D{{Key: "id", Value: "$_id"}, {Key: "email", Value: "$email"}}}}},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.M{"total": bson.M{"$gt": 1}}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var colisoes []string
+	for cursor.Next(ctx) {
+		var grupo struct {
+			Email  string `bson:"_id"`
+			Contas []struct {
+				ID    any    `bson:"id"`
+				Email string `bson:"email"`
+			} `bson:"contas"`
+		}
+		if err := cursor.Decode(&grupo); err != nil {
+			return nil, err
+		}
+
+		contas := make([]string, len(grupo.Contas))
+		for i, conta := range grupo.Contas {
+			contas[i] = fmt.Sprintf("%v %q", conta.ID, conta.Email)
+		}
+		colisoes = append(colisoes, fmt.Sprintf("%s: %s", grupo.Email, strings.Join(contas, ", ")))
+	}
+	return colisoes, cursor.Err()
+}
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/internal/mongotest"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func emails(t *testing.T, colecao *mongo.Collection) []string {
+	t.Helper()
+
+	var docs []struct {
+		Email string `bson:"email"`
+	}
+	cursor, err := colecao.Find(context.Background(), bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cursor.All(context.Background(), &docs); err != nil {
+		t.Fatal(err)
+	}
+	var lista []string
+	for _, d := range docs {
+		lista = append(lista, d.Email)
+	}
+	return lista
+}
+
+func TestNormalizarEmails(t *testing.T) {
+	db := mongotest.Banco(t)
+	ctx := context.Background()
+
+	_, err := db.Collection("clients").InsertMany(ctx, []any{
+		bson.M{"_id": bson.NewObjectID(), "email": " Ana@Calu.com "},
+		bson.M{"_id": bson.NewObjectID(), "email": "bia@calu.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Collection("locations").InsertOne(ctx, bson.M{"email": "ANA@calu.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := normalizarEmails(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	// De novo, sem efeito
+	if err := normalizarEmails(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(emails(t, db.Collection("clients")), ","); got != "ana@calu.com,bia@calu.com" {
+		t.Fatalf("clients = %s", got)
+	}
+	if got := strings.Join(emails(t, db.Collection("locations")), ","); got != "ana@calu.com" {
+		t.Fatalf("locations = %s", got)
+	}
+}
+
+func TestNormalizarEmailsRecusaColisoes(t *testing.T) {
+	db := mongotest.Banco(t)
+	ctx := context.Background()
+
+	_, err := db.Collection("clients").InsertMany(ctx, []any{
+		bson.M{"_id": bson.NewObjectID(), "email": "ana@calu.com"},
+		bson.M{"_id": bson.NewObjectID(), "email": "Ana@Calu.com"},
+		bson.M{"_id": bson.NewObjectID(), "email": "Bia@calu.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = normalizarEmails(ctx, db)
+	if err == nil || !strings.Contains(err.Error(), "ana@calu.com") || !strings.Contains(err.Error(), `"Ana@Calu.com"`) {
+		t.Fatalf("erro = %v, esperada a colisão de ana@calu.com", err)
+	}
+	if strings.Contains(err.Error(), "bia@calu.com") {
+		t.Fatalf("bia@calu.com não colide: %v", err)
+	}
+
+	// Nada é alterado enquanto houver colisões
+	if got := strings.Join(emails(t, db.Collection("clients")), ","); got != "ana@calu.com,Ana@Calu.com,Bia@calu.com" {
+		t.Fatalf("clients = %s", got)
+	}
+}
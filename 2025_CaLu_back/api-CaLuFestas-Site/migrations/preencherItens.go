@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// preencherItens preenche o _id do produto nos itens das locações antigas,
+// que só referenciavam o produto pelo nome. Itens cujo nome não corresponde a
+// nenhum produto ficam como estão e são listados no log.
+func preencherItens(ctx context.Context, db *mongo.Database) error {
+	cursor, err := db.Collection("locations").Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	porNome := map[string]bson.ObjectID{}
+	existe := map[bson.ObjectID]bool{}
+
+	var alteradas, pendentes int
+	for cursor.Next(ctx) {
+		var locacao models.Locacao
+		if err := cursor.Decode(&locacao); err != nil {
+			return err
+		}
+
+		mudou := false
+		for i, item := range locacao.Items {
+			if !item.ProdutoID.IsZero() {
+				if _, ok := existe[item.ProdutoID]; !ok {
+					n, err := db.Collection("produtos").CountDocuments(ctx, bson.M{"_id": item.ProdutoID})
+					if err != nil {
+						return err
+					}
+					existe[item.ProdutoID] = n > 0
+				}
+				if existe[item.ProdutoID] {
+					continue
+				}
+			}
+
+			id, ok := porNome[item.Nome]
+			if !ok {
+				var produto models.Product
+				err := db.Collection("produtos").FindOne(ctx, bson.M{"nome": item.Nome}).Decode(&produto)
+				if err != nil && err != mongo.ErrNoDocuments {
+					return err
+				}
+				id = produto.ID
+				porNome[item.Nome] = id
+			}
+
+			if id.IsZero() {
+				log.Printf("locação %s: produto %q não encontrado", locacao.ID.Hex(), item.Nome)
+				pendentes++
+				continue
+			}
+
+			locacao.Items[i].ProdutoID = id
+			mudou = true
+		}
+
+		if !mudou {
+			continue
+		}
+		alteradas++
+
+		_, err := db.Collection("locations").UpdateOne(ctx, bson.M{"_id": locacao.ID}, bson.M{"$set": bson.M{"items": locacao.Items}})
+		if err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("%d locações atualizadas, %d itens sem produto correspondente", alteradas, pendentes)
+	return nil
+}
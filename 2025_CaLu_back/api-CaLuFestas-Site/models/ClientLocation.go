@@ -1,5 +1,11 @@
 package models
 
+// ClientLocation é o corpo com que um administrador consulta as locações de
+// um cliente.
 type ClientLocation struct {
-	Email        string             `json:"email" bson:"email"`
-}
\ No newline at end of file
+	Email string `json:"email" bson:"email" binding:"required,email"`
+}
+
+func (r *ClientLocation) Normalizar() {
+	r.Email = NormalizeEmail(r.Email)
+}
@@ -1,6 +1,11 @@
 package models
 
 type PasswordReset struct {
-	Email       string `json:"email"`
-	Password     string `json:"password"`
-}
\ No newline at end of file
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	ResetToken string `json:"reset_token" binding:"required"`
+}
+
+func (r *PasswordReset) Normalizar() {
+	r.Email = NormalizeEmail(r.Email)
+}
@@ -1,13 +1,186 @@
 package models
 
 import (
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	CargoAdmin = "admin"
+	CargoUser  = "user"
 )
 
+// Status da conta. Contas anteriores à verificação de e-mail não têm status
+// gravado e são tratadas como ativas.
+const (
+	StatusPendente = "pendente"
+	StatusAtivo    = "ativo"
+	// Contas desativadas por um administrador não conseguem logar nem usar
+	// tokens já emitidos
+	StatusDesativado = "desativado"
+)
+
+// Client é o documento gravado em "clients". Senha guarda o hash e nunca é
+// serializada; respostas usam ClientView.
 type Client struct {
-	ID    primitive.ObjectID `json:"_id"`
-	Nome  string             `json:"nome"`
-	Email string             `json:"email"`
-	Senha string             `json:"senha"`
-	Cargo string             `json:"cargo"`
+	ID     bson.ObjectID `json:"_id" bson:"_id"`
+	Nome   string        `json:"nome" bson:"nome"`
+	Email  string        `json:"email" bson:"email"`
+	Senha  string        `json:"-" bson:"senha"`
+	Cargo  string        `json:"cargo" bson:"cargo"`
+	Status string        `json:"status" bson:"status"`
+	// Telefone e Documento são gravados só com os dígitos
+	Telefone  string          `json:"telefone" bson:"telefone"`
+	Documento string          `json:"documento" bson:"documento"`
+	Enderecos []EnderecoSalvo `json:"enderecos" bson:"enderecos"`
+}
+
+// EnderecoSalvo é um endereço de entrega guardado no perfil para ser
+// reutilizado em novas locações.
+type EnderecoSalvo struct {
+	ID       bson.ObjectID `json:"_id" bson:"_id"`
+	Apelido  string        `json:"apelido" bson:"apelido"`
+	Endereco `bson:",inline"`
+}
+
+// ClientView é o que a API devolve sobre um cliente.
+type ClientView struct {
+	ID        bson.ObjectID   `json:"_id"`
+	Nome      string          `json:"nome"`
+	Email     string          `json:"email"`
+	Cargo     string          `json:"cargo"`
+	Status    string          `json:"status"`
+	Telefone  string          `json:"telefone"`
+	Documento string          `json:"documento"`
+	Enderecos []EnderecoSalvo `json:"enderecos"`
+}
+
+func (c Client) View() ClientView {
+	status := c.Status
+	if status == "" {
+		status = StatusAtivo
+	}
+	enderecos := c.Enderecos
+	if enderecos == nil {
+		enderecos = []EnderecoSalvo{}
+	}
+	return ClientView{
+		ID:        c.ID,
+		Nome:      c.Nome,
+		Email:     c.Email,
+		Cargo:     c.Cargo,
+		Status:    status,
+		Telefone:  c.Telefone,
+		Documento: c.Documento,
+		Enderecos: enderecos,
+	}
+}
+
+// ProfilePatch traz os campos do perfil que o próprio cliente pode alterar;
+// nil significa "não alterar". Enderecos substitui a lista inteira.
+type ProfilePatch struct {
+	Nome      *string               `json:"nome" binding:"omitempty,max=100"`
+	Telefone  *string               `json:"telefone"`
+	Documento *string               `json:"documento"`
+	Enderecos *[]EnderecoSalvoInput `json:"enderecos" binding:"omitempty,max=10,dive"`
+}
+
+// EnderecoSalvoInput é um endereço enviado no PATCH do perfil. Com o _id de
+// um endereço já salvo ele mantém o ID; sem _id é um endereço novo.
+type EnderecoSalvoInput struct {
+	ID      bson.ObjectID `json:"_id"`
+	Apelido string        `json:"apelido" binding:"max=50"`
+	Endereco
+}
+
+type ChangePasswordRequest struct {
+	SenhaAtual string `json:"senha_atual" binding:"required"`
+	NovaSenha  string `json:"nova_senha" binding:"required"`
+}
+
+// DeleteAccountRequest exige a senha para que um token vazado não baste
+// para apagar a conta.
+type DeleteAccountRequest struct {
+	Senha string `json:"senha" binding:"required"`
+}
+
+func (c Client) EmailVerificado() bool {
+	return c.Status != StatusPendente
+}
+
+func (c Client) Desativado() bool {
+	return c.Status == StatusDesativado
+}
+
+type CargoRequest struct {
+	Cargo string `json:"cargo" binding:"required,oneof=admin user"`
+}
+
+// RegisterRequest é o corpo aceito no cadastro. ID, cargo e status são
+// sempre definidos pelo servidor.
+type RegisterRequest struct {
+	Nome  string `json:"nome" binding:"required,max=100"`
+	Email string `json:"email" binding:"required,email,max=254"`
+	Senha string `json:"senha" binding:"required"`
+}
+
+type LoginRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Senha string `json:"senha" binding:"required"`
+}
+
+// Normalizar apara os campos antes da validação, para que " Ana@Calu.com "
+// passe na regra de e-mail e um nome só de espaços conte como vazio.
+func (r *RegisterRequest) Normalizar() {
+	r.Nome = strings.TrimSpace(r.Nome)
+	r.Email = NormalizeEmail(r.Email)
+}
+
+func (r *LoginRequest) Normalizar() {
+	r.Email = NormalizeEmail(r.Email)
+}
+
+// NormalizeEmail deixa o e-mail na forma em que é gravado e consultado.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+const (
+	tamanhoMinimoSenha = 8
+	// bcrypt ignora o que passa de 72 bytes
+	tamanhoMaximoSenha = 72
+)
+
+var (
+	ErrSenhaCurta = errors.New("a senha deve ter pelo menos 8 caracteres")
+	ErrSenhaLonga = errors.New("a senha deve ter no máximo 72 bytes")
+	ErrSenhaFraca = errors.New("a senha deve conter letras e números")
+)
+
+// ValidarSenha aplica a política de senhas do cadastro e da redefinição.
+func ValidarSenha(senha string) error {
+	if utf8.RuneCountInString(senha) < tamanhoMinimoSenha {
+		return ErrSenhaCurta
+	}
+	if len(senha) > tamanhoMaximoSenha {
+		return ErrSenhaLonga
+	}
+
+	var letra, digito bool
+	for _, r := range senha {
+		switch {
+		case unicode.IsLetter(r):
+			letra = true
+		case unicode.IsDigit(r):
+			digito = true
+		}
+	}
+	if !letra || !digito {
+		return ErrSenhaFraca
+	}
+	return nil
 }
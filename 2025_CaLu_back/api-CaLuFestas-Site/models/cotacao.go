@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Cotacao guarda um orçamento emitido sem reservar estoque. Enquanto não
+// expirar, a locação com os mesmos itens e período pode usá-la para manter o
+// preço cotado.
+type Cotacao struct {
+	ID     bson.ObjectID `json:"_id" bson:"_id"`
+	Items  []Item        `json:"items" bson:"items"`
+	Inicio time.Time     `json:"inicio" bson:"inicio"`
+	Fim    time.Time     `json:"fim" bson:"fim"`
+	// O frete cotado depende do endereço, então ele também precisa bater
+	EnderecoEntrega *Endereco `json:"endereco_entrega,omitempty" bson:"endereco_entrega,omitempty"`
+	Orcamento       Orcamento `json:"orcamento" bson:"orcamento"`
+	CriadaEm        time.Time `json:"criada_em" bson:"criada_em"`
+	ExpiraEm        time.Time `json:"expira_em" bson:"expira_em"`
+	Usada           bool      `json:"usada" bson:"usada"`
+}
+
+// Corresponde verifica se a locação pede exatamente os produtos, as
+// quantidades, o período e o local de entrega que foram cotados.
+func (c Cotacao) Corresponde(locacao Locacao) bool {
+	if !c.Inicio.Equal(locacao.Inicio) || !c.Fim.Equal(locacao.Fim) {
+		return false
+	}
+	if !mesmoLocal(c.EnderecoEntrega, locacao.EnderecoEntrega) {
+		return false
+	}
+
+	quantidades := map[bson.ObjectID]int{}
+	for _, item := range c.Items {
+		quantidades[item.ProdutoID] += item.Quantidade
+	}
+	for _, item := range locacao.Items {
+		quantidades[item.ProdutoID] -= item.Quantidade
+	}
+	for _, q := range quantidades {
+		if q != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mesmoLocal compara só o que define a zona de entrega; número e complemento
+// podem mudar sem alterar o frete.
+func mesmoLocal(a, b *Endereco) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.CEP == b.CEP && a.Bairro == b.Bairro && a.Cidade == b.Cidade && a.UF == b.UF
+}
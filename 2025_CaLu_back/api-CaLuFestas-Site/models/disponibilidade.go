@@ -0,0 +1,139 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Formatos aceitos para datas de locação. O site envia "dd-mm-aaaa hh:mm";
+// os demais existem para facilitar consultas diretas na API.
+var layoutsData = []string{
+	"02-01-2006 15:04",
+	"02-01-2006",
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var (
+	ErrDataInvalida    = errors.New("data inválida, use dd-mm-aaaa hh:mm")
+	ErrPeriodoInvalido = errors.New("a retirada deve ser posterior à entrega")
+)
+
+func ParseData(valor string) (time.Time, error) {
+	valor = strings.TrimSpace(valor)
+	for _, layout := range layoutsData {
+		if t, err := time.Parse(layout, valor); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, ErrDataInvalida
+}
+
+// ParsePeriodo converte um par de datas em um intervalo [inicio, fim).
+func ParsePeriodo(entrega, retirada string) (time.Time, time.Time, error) {
+	inicio, err := ParseData(entrega)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	fim, err := ParseData(retirada)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !fim.After(inicio) {
+		return time.Time{}, time.Time{}, ErrPeriodoInvalido
+	}
+	return inicio, fim, nil
+}
+
+// Periodo devolve o intervalo ocupado pela locação, usando os campos já
+// calculados quando existirem e as datas em texto para documentos antigos.
+func (l Locacao) Periodo() (time.Time, time.Time, error) {
+	if !l.Inicio.IsZero() && !l.Fim.IsZero() {
+		return l.Inicio, l.Fim, nil
+	}
+	return ParsePeriodo(l.DataEntrega, l.DataRetirada)
+}
+
+func Sobrepoe(inicioA, fimA, inicioB, fimB time.Time) bool {
+	return inicioA.Before(fimB) && inicioB.Before(fimA)
+}
+
+// Reserva representa as unidades de um produto presas por uma locação.
+type Reserva struct {
+	Inicio     time.Time
+	Fim        time.Time
+	Quantidade int
+}
+
+type Disponibilidade struct {
+	ProdutoID  bson.ObjectID `json:"produto_id"`
+	Nome       string        `json:"nome"`
+	Inicio     time.Time     `json:"inicio"`
+	Fim        time.Time     `json:"fim"`
+	Quantidade int           `json:"quantidade"`
+	Reservada  int           `json:"reservada"`
+	Disponivel int           `json:"disponivel"`
+	Solicitada int           `json:"solicitada,omitempty"`
+}
+
+// CalcularDisponibilidade considera o pico de unidades reservadas ao mesmo
+// tempo dentro de [inicio, fim), e não a soma de todas as reservas do período:
+// duas festas em fins de semana diferentes não disputam as mesmas unidades.
+func CalcularDisponibilidade(produto Product, inicio, fim time.Time, reservas []Reserva) Disponibilidade {
+	type evento struct {
+		quando time.Time
+		delta  int
+	}
+
+	var eventos []evento
+	for _, r := range reservas {
+		if r.Quantidade <= 0 || !Sobrepoe(r.Inicio, r.Fim, inicio, fim) {
+			continue
+		}
+		de, ate := r.Inicio, r.Fim
+		if de.Before(inicio) {
+			de = inicio
+		}
+		if ate.After(fim) {
+			ate = fim
+		}
+		eventos = append(eventos, evento{de, r.Quantidade}, evento{ate, -r.Quantidade})
+	}
+
+	// Em um mesmo instante, devoluções são processadas antes das saídas.
+	sort.Slice(eventos, func(i, j int) bool {
+		if eventos[i].quando.Equal(eventos[j].quando) {
+			return eventos[i].delta < eventos[j].delta
+		}
+		return eventos[i].quando.Before(eventos[j].quando)
+	})
+
+	atual, pico := 0, 0
+	for _, e := range eventos {
+		atual += e.delta
+		if atual > pico {
+			pico = atual
+		}
+	}
+
+	disponivel := produto.Quantidade - pico
+	if disponivel < 0 {
+		disponivel = 0
+	}
+
+	return Disponibilidade{
+		ProdutoID:  produto.ID,
+		Nome:       produto.Nome,
+		Inicio:     inicio,
+		Fim:        fim,
+		Quantidade: produto.Quantidade,
+		Reservada:  pico,
+		Disponivel: disponivel,
+	}
+}
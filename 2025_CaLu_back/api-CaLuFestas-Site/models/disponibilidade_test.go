@@ -0,0 +1,179 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func dia(d int, hora int) time.Time {
+	return time.Date(2030, time.January, d, hora, 0, 0, 0, time.UTC)
+}
+
+func TestCalcularDisponibilidade(t *testing.T) {
+	mesa := Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 10}
+
+	casos := []struct {
+		nome      string
+		inicio    time.Time
+		fim       time.Time
+		reservas  []Reserva
+		reservada int
+	}{
+		{
+			nome:   "sem reservas",
+			inicio: dia(10, 0), fim: dia(12, 0),
+			reservada: 0,
+		},
+		{
+			// A retirada de uma festa no mesmo instante da entrega da seguinte
+			// libera as unidades a tempo
+			nome:   "locações encostadas",
+			inicio: dia(10, 0), fim: dia(14, 0),
+			reservas: []Reserva{
+				{Inicio: dia(10, 0), Fim: dia(12, 0), Quantidade: 6},
+				{Inicio: dia(12, 0), Fim: dia(14, 0), Quantidade: 6},
+			},
+			reservada: 6,
+		},
+		{
+			nome:   "sobreposições parciais somam no pico",
+			inicio: dia(10, 0), fim: dia(20, 0),
+			reservas: []Reserva{
+				{Inicio: dia(10, 0), Fim: dia(13, 0), Quantidade: 3},
+				{Inicio: dia(12, 0), Fim: dia(15, 0), Quantidade: 4},
+				{Inicio: dia(14, 0), Fim: dia(18, 0), Quantidade: 2},
+			},
+			reservada: 7,
+		},
+		{
+			nome:   "reservas fora do período não contam",
+			inicio: dia(10, 0), fim: dia(12, 0),
+			reservas: []Reserva{
+				{Inicio: dia(5, 0), Fim: dia(10, 0), Quantidade: 9},
+				{Inicio: dia(12, 0), Fim: dia(15, 0), Quantidade: 9},
+			},
+			reservada: 0,
+		},
+		{
+			nome:   "reserva que começa antes do período",
+			inicio: dia(10, 0), fim: dia(12, 0),
+			reservas: []Reserva{
+				{Inicio: dia(8, 0), Fim: dia(11, 0), Quantidade: 5},
+				{Inicio: dia(11, 0), Fim: dia(12, 0), Quantidade: 2},
+			},
+			reservada: 5,
+		},
+		{
+			nome:   "mais reservado que o estoque",
+			inicio: dia(10, 0), fim: dia(12, 0),
+			reservas: []Reserva{
+				{Inicio: dia(10, 0), Fim: dia(12, 0), Quantidade: 8},
+				{Inicio: dia(11, 0), Fim: dia(12, 0), Quantidade: 8},
+			},
+			reservada: 16,
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			d := CalcularDisponibilidade(mesa, caso.inicio, caso.fim, caso.reservas)
+			disponivel := max(mesa.Quantidade-caso.reservada, 0)
+			if d.Reservada != caso.reservada || d.Disponivel != disponivel {
+				t.Fatalf("reservada = %d, disponível = %d; esperado %d e %d", d.Reservada, d.Disponivel, caso.reservada, disponivel)
+			}
+		})
+	}
+}
+
+func TestPeriodoDeLocacaoAntiga(t *testing.T) {
+	casos := []struct {
+		nome    string
+		locacao Locacao
+		inicio  time.Time
+		fim     time.Time
+		erro    bool
+	}{
+		{
+			nome:    "campos calculados",
+			locacao: Locacao{Inicio: dia(10, 8), Fim: dia(12, 18), DataEntrega: "01-01-2000"},
+			inicio:  dia(10, 8), fim: dia(12, 18),
+		},
+		{
+			nome:    "sem inicio, datas em texto",
+			locacao: Locacao{DataEntrega: "10-01-2030 08:00", DataRetirada: "12-01-2030 18:00"},
+			inicio:  dia(10, 8), fim: dia(12, 18),
+		},
+		{
+			nome:    "sem inicio, só o dia",
+			locacao: Locacao{DataEntrega: "10-01-2030", DataRetirada: "12-01-2030"},
+			inicio:  dia(10, 0), fim: dia(12, 0),
+		},
+		{
+			nome:    "texto ilegível",
+			locacao: Locacao{DataEntrega: "amanhã", DataRetirada: "12-01-2030"},
+			erro:    true,
+		},
+		{
+			nome:    "retirada antes da entrega",
+			locacao: Locacao{DataEntrega: "12-01-2030", DataRetirada: "10-01-2030"},
+			erro:    true,
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			inicio, fim, err := caso.locacao.Periodo()
+			if caso.erro {
+				if err == nil {
+					t.Fatalf("período %v a %v aceito", inicio, fim)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !inicio.Equal(caso.inicio) || !fim.Equal(caso.fim) {
+				t.Fatalf("período = %v a %v, esperado %v a %v", inicio, fim, caso.inicio, caso.fim)
+			}
+		})
+	}
+}
+
+// O formato é o que o site lê em /products/:id/availability.
+func TestDisponibilidadeJSON(t *testing.T) {
+	id := bson.NewObjectID()
+	d := CalcularDisponibilidade(Product{ID: id, Nome: "Mesa", Quantidade: 10}, dia(10, 0), dia(12, 0),
+		[]Reserva{{Inicio: dia(10, 0), Fim: dia(11, 0), Quantidade: 4}})
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var corpo map[string]any
+	if err := json.Unmarshal(b, &corpo); err != nil {
+		t.Fatal(err)
+	}
+
+	esperado := map[string]any{
+		"produto_id": id.Hex(),
+		"nome":       "Mesa",
+		"inicio":     "2030-01-10T00:00:00Z",
+		"fim":        "2030-01-12T00:00:00Z",
+		"quantidade": 10.0,
+		"reservada":  4.0,
+		"disponivel": 6.0,
+	}
+	if !reflect.DeepEqual(corpo, esperado) {
+		t.Fatalf("JSON = %s", b)
+	}
+
+	d.Solicitada = 7
+	if b, _ := json.Marshal(d); !strings.Contains(string(b), `"solicitada":7`) {
+		t.Fatalf("solicitada ausente: %s", b)
+	}
+}
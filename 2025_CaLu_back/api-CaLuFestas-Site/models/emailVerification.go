@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// EmailVerification é o código enviado para confirmar o e-mail de uma conta
+// nova. Existe no máximo um por e-mail; reenviar substitui o anterior.
+type EmailVerification struct {
+	ID        bson.ObjectID `json:"_id" bson:"_id"`
+	Email     string        `json:"email" bson:"email"`
+	OTPHash   string        `json:"-" bson:"otp_hash"`
+	SentAt    time.Time     `json:"sent_at" bson:"sent_at"`
+	ExpiresAt time.Time     `json:"expires_at" bson:"expires_at"`
+	Attempts  int           `json:"attempts" bson:"attempts"`
+}
+
+type EmailVerificationRequest struct {
+	Email   string `json:"email" binding:"required"`
+	OTPCode string `json:"otp_code" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrCEPInvalido = errors.New("CEP inválido")
+
+var ufs = map[string]bool{
+	"AC": true, "AL": true, "AP": true, "AM": true, "BA": true, "CE": true, "DF": true,
+	"ES": true, "GO": true, "MA": true, "MT": true, "MS": true, "MG": true, "PA": true,
+	"PB": true, "PR": true, "PE": true, "PI": true, "RJ": true, "RN": true, "RS": true,
+	"RO": true, "RR": true, "SC": true, "SP": true, "SE": true, "TO": true,
+}
+
+// Endereco é um endereço de entrega estruturado. CEP é gravado só com os
+// dígitos.
+type Endereco struct {
+	CEP         string `json:"cep" bson:"cep"`
+	Logradouro  string `json:"logradouro" bson:"logradouro"`
+	Numero      string `json:"numero" bson:"numero"`
+	Complemento string `json:"complemento" bson:"complemento"`
+	Bairro      string `json:"bairro" bson:"bairro"`
+	Cidade      string `json:"cidade" bson:"cidade"`
+	UF          string `json:"uf" bson:"uf"`
+}
+
+// NormalizarCEP aceita "01310-100" ou "01310100" e devolve os 8 dígitos.
+func NormalizarCEP(cep string) (string, error) {
+	var b strings.Builder
+	for _, r := range cep {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '-' || r == '.' || r == ' ':
+		default:
+			return "", ErrCEPInvalido
+		}
+	}
+	if b.Len() != 8 {
+		return "", ErrCEPInvalido
+	}
+	return b.String(), nil
+}
+
+// Normalizar limpa espaços, o CEP e a UF. Deve ser chamado antes de Validar.
+func (e *Endereco) Normalizar() {
+	e.Logradouro = strings.TrimSpace(e.Logradouro)
+	e.Numero = strings.TrimSpace(e.Numero)
+	e.Complemento = strings.TrimSpace(e.Complemento)
+	e.Bairro = strings.TrimSpace(e.Bairro)
+	e.Cidade = strings.TrimSpace(e.Cidade)
+	e.UF = strings.ToUpper(strings.TrimSpace(e.UF))
+	if cep, err := NormalizarCEP(e.CEP); err == nil {
+		e.CEP = cep
+	}
+}
+
+// Validar devolve os campos inválidos, indexados pelo nome no JSON. Um mapa
+// vazio significa endereço válido.
+func (e Endereco) Validar() map[string]string {
+	campos := map[string]string{}
+	if _, err := NormalizarCEP(e.CEP); err != nil {
+		campos["cep"] = err.Error()
+	}
+	if e.Logradouro == "" {
+		campos["logradouro"] = "campo obrigatório"
+	}
+	if e.Numero == "" {
+		campos["numero"] = "campo obrigatório"
+	}
+	if e.Bairro == "" {
+		campos["bairro"] = "campo obrigatório"
+	}
+	if e.Cidade == "" {
+		campos["cidade"] = "campo obrigatório"
+	}
+	if !ufs[e.UF] {
+		campos["uf"] = "UF inválida"
+	}
+	return campos
+}
+
+// String formata o endereço em uma linha, como era gravado no campo texto
+// das locações.
+func (e Endereco) String() string {
+	linha := e.Logradouro + ", " + e.Numero
+	if e.Complemento != "" {
+		linha += " - " + e.Complemento
+	}
+	linha += " - " + e.Bairro + ", " + e.Cidade + "/" + e.UF
+	if len(e.CEP) == 8 {
+		linha += " - CEP " + e.CEP[:5] + "-" + e.CEP[5:]
+	}
+	return linha
+}
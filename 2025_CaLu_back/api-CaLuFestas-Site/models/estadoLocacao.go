@@ -0,0 +1,72 @@
+package models
+
+// EstadoLocacao é a etapa em que uma locação se encontra. As mudanças de
+// estado só podem seguir as transições de transicoesLocacao.
+type EstadoLocacao string
+
+const (
+	EstadoEmAnalise EstadoLocacao = "Em analise"
+	EstadoAprovada  EstadoLocacao = "Aprovada"
+	EstadoEntregue  EstadoLocacao = "Entregue"
+	EstadoRetirada  EstadoLocacao = "Retirada"
+	EstadoConcluida EstadoLocacao = "Concluida"
+	EstadoRecusada  EstadoLocacao = "Recusada"
+	EstadoCancelada EstadoLocacao = "Cancelada"
+)
+
+var transicoesLocacao = map[EstadoLocacao][]EstadoLocacao{
+	EstadoEmAnalise: {EstadoAprovada, EstadoRecusada, EstadoCancelada},
+	EstadoAprovada:  {EstadoEntregue, EstadoCancelada},
+	EstadoEntregue:  {EstadoRetirada},
+	EstadoRetirada:  {EstadoConcluida},
+	EstadoConcluida: {},
+	EstadoRecusada:  {},
+	EstadoCancelada: {},
+}
+
+// EstadosSemReserva lista os estados de locação que já devolveram os itens ao estoque.
+var EstadosSemReserva = []EstadoLocacao{EstadoConcluida, EstadoRecusada, EstadoCancelada}
+
+func (e EstadoLocacao) Valido() bool {
+	_, ok := transicoesLocacao[e]
+	return ok
+}
+
+// Proximos devolve os estados para os quais a locação pode seguir.
+func (e EstadoLocacao) Proximos() []EstadoLocacao {
+	return transicoesLocacao[e]
+}
+
+func (e EstadoLocacao) PodeIrPara(novo EstadoLocacao) bool {
+	for _, permitido := range transicoesLocacao[e] {
+		if permitido == novo {
+			return true
+		}
+	}
+	return false
+}
+
+// ReservaEstoque indica se a locação ainda prende as unidades dos seus itens.
+// Estados desconhecidos, vindos de documentos antigos, são tratados como
+// reservados para não liberar estoque por engano.
+func (e EstadoLocacao) ReservaEstoque() bool {
+	for _, liberado := range EstadosSemReserva {
+		if e == liberado {
+			return false
+		}
+	}
+	return true
+}
+
+// EfeitoEstoque diz o que a transição de para novo faz com o contador de itens
+// em locação: 1 prende as unidades, -1 devolve e 0 não altera. Como cada
+// transição só pode ocorrer uma vez, o efeito também só é aplicado uma vez.
+func (e EstadoLocacao) EfeitoEstoque(novo EstadoLocacao) int {
+	switch {
+	case e.ReservaEstoque() && !novo.ReservaEstoque():
+		return -1
+	case !e.ReservaEstoque() && novo.ReservaEstoque():
+		return 1
+	}
+	return 0
+}
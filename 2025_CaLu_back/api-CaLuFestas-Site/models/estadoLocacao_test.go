@@ -0,0 +1,54 @@
+package models
+
+import "testing"
+
+func TestEfeitoEstoque(t *testing.T) {
+	// Estados que prendem unidades, incluindo um desconhecido vindo de
+	// documentos antigos
+	reservam := []EstadoLocacao{EstadoEmAnalise, EstadoAprovada, EstadoEntregue, EstadoRetirada, "Pendente"}
+	liberam := []EstadoLocacao{EstadoConcluida, EstadoRecusada, EstadoCancelada}
+
+	casos := []struct {
+		de, para []EstadoLocacao
+		efeito   int
+	}{
+		{reservam, reservam, 0},
+		{reservam, liberam, -1},
+		{liberam, reservam, 1},
+		{liberam, liberam, 0},
+	}
+	for _, caso := range casos {
+		for _, de := range caso.de {
+			for _, para := range caso.para {
+				if got := de.EfeitoEstoque(para); got != caso.efeito {
+					t.Errorf("%s -> %s: efeito %d, esperado %d", de, para, got, caso.efeito)
+				}
+			}
+		}
+	}
+}
+
+// Cada transição permitida tem no máximo um efeito no estoque e nenhum estado
+// final sai do lugar.
+func TestTransicoesLocacao(t *testing.T) {
+	for de, proximos := range transicoesLocacao {
+		for _, para := range proximos {
+			if !para.Valido() {
+				t.Errorf("%s -> %s: destino desconhecido", de, para)
+			}
+			if de.EfeitoEstoque(para) == 1 {
+				t.Errorf("%s -> %s: volta a prender o estoque", de, para)
+			}
+		}
+		if !de.ReservaEstoque() && len(proximos) > 0 {
+			t.Errorf("%s devolveu o estoque mas ainda pode ir para %v", de, proximos)
+		}
+	}
+
+	if EstadoEmAnalise.PodeIrPara(EstadoConcluida) || EstadoConcluida.PodeIrPara(EstadoConcluida) {
+		t.Fatal("transição fora da tabela aceita")
+	}
+	if !EstadoRetirada.PodeIrPara(EstadoConcluida) {
+		t.Fatal("Retirada deveria poder ir para Concluida")
+	}
+}
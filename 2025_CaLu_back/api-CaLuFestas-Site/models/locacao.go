@@ -1,25 +1,118 @@
 package models
 
 import (
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// Item é um produto pedido em uma locação. ProdutoID é a chave usada para
+// estoque e preço; Nome e Preco são só uma cópia do catálogo no momento do
+// pedido.
 type Item struct {
-	ID         primitive.ObjectID `json:"_id" bson:"_id"`
-	Nome       string             `json:"nome" bson:"nome"`
-	Preco      float64            `json:"preco" bson:"preco"`
-	Quantidade int                `json:"quantidade" bson:"quantidade"`
+	ProdutoID  bson.ObjectID `json:"_id" bson:"_id"`
+	Nome       string        `json:"nome" bson:"nome"`
+	Preco      float64       `json:"preco" bson:"preco"`
+	Quantidade int           `json:"quantidade" bson:"quantidade"`
+}
+
+// ItemInput é um item como enviado pelo cliente; nome e preço vêm sempre do
+// catálogo.
+type ItemInput struct {
+	ProdutoID  bson.ObjectID `json:"_id" binding:"required"`
+	Quantidade int           `json:"quantidade" binding:"required,min=1"`
+}
+
+// LocacaoInput é o corpo aceito ao pedir uma locação ou uma cotação. Dono,
+// estado, período normalizado e total são definidos pelo servidor.
+type LocacaoInput struct {
+	Nome     string `json:"nome" binding:"max=200"`
+	Endereco string `json:"endereco" binding:"max=500"`
+	// EnderecoEntrega substitui o campo texto; quando enviado, Endereco é
+	// preenchido a partir dele
+	EnderecoEntrega *Endereco   `json:"endereco_entrega"`
+	DataEntrega     string      `json:"data_entrega" binding:"required"`
+	DataRetirada    string      `json:"data_retirada" binding:"required"`
+	Pagamento       string      `json:"pagamento" binding:"max=100"`
+	Items           []ItemInput `json:"items" binding:"required,min=1,dive"`
+	Cotacao         string      `json:"quote_id"`
+}
+
+// Locacao monta a locação a partir do pedido, sem ID, dono ou estado.
+func (in LocacaoInput) Locacao() Locacao {
+	items := make([]Item, len(in.Items))
+	for i, item := range in.Items {
+		items[i] = Item{ProdutoID: item.ProdutoID, Quantidade: item.Quantidade}
+	}
+	return Locacao{
+		Nome:            in.Nome,
+		Endereco:        in.Endereco,
+		EnderecoEntrega: in.EnderecoEntrega,
+		DataEntrega:     in.DataEntrega,
+		DataRetirada:    in.DataRetirada,
+		Pagamento:       in.Pagamento,
+		Items:           items,
+		Cotacao:         in.Cotacao,
+	}
 }
 
 type Locacao struct {
-	ID           primitive.ObjectID `json:"_id" bson:"_id"`
-	Nome         string             `json:"nome" bson:"nome"`
-	Endereco     string             `json:"endereco" bson:"endereco"`
-	Email        string             `json:"email" bson:"email"`
-	DataEntrega  string             `json:"data_entrega" bson:"data_entrega"`
-	DataRetirada string             `json:"data_retirada" bson:"data_retirada"`
-	Pagamento    string             `json:"pagamento" bson:"pagamento"`
-	Total        float64            `json:"total" bson:"total"`
-	Items        []Item             `json:"items" bson:"items"`
-	Estado       string             `json:"estado" bson:"estado"`
+	ID       bson.ObjectID `json:"_id" bson:"_id"`
+	Nome     string        `json:"nome" bson:"nome"`
+	Endereco string        `json:"endereco" bson:"endereco"`
+	// Locações antigas só têm o endereço em texto
+	EnderecoEntrega *Endereco `json:"endereco_entrega,omitempty" bson:"endereco_entrega,omitempty"`
+	Email           string    `json:"email" bson:"email"`
+	DataEntrega     string    `json:"data_entrega" bson:"data_entrega"`
+	DataRetirada    string    `json:"data_retirada" bson:"data_retirada"`
+	Pagamento       string    `json:"pagamento" bson:"pagamento"`
+	Total           float64   `json:"total" bson:"total"`
+	// Frete é a parte do Total cobrada pela entrega e retirada
+	Frete   float64       `json:"frete" bson:"frete"`
+	Items   []Item        `json:"items" bson:"items"`
+	Estado  EstadoLocacao `json:"estado" bson:"estado"`
+	Inicio  time.Time     `json:"inicio" bson:"inicio"`
+	Fim     time.Time     `json:"fim" bson:"fim"`
+	Cotacao string        `json:"quote_id,omitempty" bson:"-"`
+}
+
+// LocacaoView é o que a API devolve sobre uma locação.
+type LocacaoView struct {
+	ID              bson.ObjectID `json:"_id"`
+	Nome            string        `json:"nome"`
+	Endereco        string        `json:"endereco"`
+	EnderecoEntrega *Endereco     `json:"endereco_entrega,omitempty"`
+	Email           string        `json:"email"`
+	DataEntrega     string        `json:"data_entrega"`
+	DataRetirada    string        `json:"data_retirada"`
+	Pagamento       string        `json:"pagamento"`
+	Total           float64       `json:"total"`
+	Frete           float64       `json:"frete"`
+	Items           []Item        `json:"items"`
+	Estado          EstadoLocacao `json:"estado"`
+	Inicio          time.Time     `json:"inicio"`
+	Fim             time.Time     `json:"fim"`
+}
+
+func (l Locacao) View() LocacaoView {
+	items := l.Items
+	if items == nil {
+		items = []Item{}
+	}
+	return LocacaoView{
+		ID:              l.ID,
+		Nome:            l.Nome,
+		Endereco:        l.Endereco,
+		EnderecoEntrega: l.EnderecoEntrega,
+		Email:           l.Email,
+		DataEntrega:     l.DataEntrega,
+		DataRetirada:    l.DataRetirada,
+		Pagamento:       l.Pagamento,
+		Total:           l.Total,
+		Frete:           l.Frete,
+		Items:           items,
+		Estado:          l.Estado,
+		Inicio:          l.Inicio,
+		Fim:             l.Fim,
+	}
 }
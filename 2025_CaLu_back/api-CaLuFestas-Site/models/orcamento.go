@@ -0,0 +1,52 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// AjusteOrcamento descreve o efeito de uma regra de preço; valores negativos
+// são descontos.
+type AjusteOrcamento struct {
+	Regra     string         `json:"regra" bson:"regra"`
+	Tipo      TipoRegraPreco `json:"tipo" bson:"tipo"`
+	Descricao string         `json:"descricao" bson:"descricao"`
+	Valor     float64        `json:"valor" bson:"valor"`
+}
+
+type ItemOrcamento struct {
+	ProdutoID     bson.ObjectID     `json:"produto_id" bson:"produto_id"`
+	Nome          string            `json:"nome" bson:"nome"`
+	PrecoUnitario float64           `json:"preco_unitario" bson:"preco_unitario"`
+	Quantidade    int               `json:"quantidade" bson:"quantidade"`
+	Dias          int               `json:"dias" bson:"dias"`
+	Subtotal      float64           `json:"subtotal" bson:"subtotal"`
+	Ajustes       []AjusteOrcamento `json:"ajustes,omitempty" bson:"ajustes,omitempty"`
+}
+
+// Orcamento é o detalhamento do preço de uma locação, sempre calculado pelo
+// servidor a partir do catálogo. Total já inclui o frete, quando houver.
+type Orcamento struct {
+	Dias    int               `json:"dias" bson:"dias"`
+	Itens   []ItemOrcamento   `json:"itens" bson:"itens"`
+	Ajustes []AjusteOrcamento `json:"ajustes,omitempty" bson:"ajustes,omitempty"`
+	Frete   *Frete            `json:"frete,omitempty" bson:"frete,omitempty"`
+	Total   float64           `json:"total" bson:"total"`
+}
+
+// DiasLocacao conta as diárias entre a entrega e a retirada. Qualquer fração
+// de dia é cobrada como uma diária inteira.
+func DiasLocacao(inicio, fim time.Time) int {
+	dias := int(math.Ceil(fim.Sub(inicio).Hours() / 24))
+	if dias < 1 {
+		return 1
+	}
+	return dias
+}
+
+// Arredondar limita um valor em reais aos centavos.
+func Arredondar(valor float64) float64 {
+	return math.Round(valor*100) / 100
+}
@@ -3,14 +3,22 @@ package models
 import (
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// PasswordResetEntry acompanha um pedido de redefinição de senha: o OTP
+// enviado por e-mail é trocado, uma única vez, por um reset token que
+// UpdatePassword consome. ExpiresAt vale para a etapa atual e também
+// alimenta o índice TTL que remove a entrada.
 type PasswordResetEntry struct {
-	ID         primitive.ObjectID `json:"id"`
-	Email      string             `json:"email"`
-	OTPCode    string             `json:"otp_code"`
-	ExpiresAt  time.Time          `json:"expires_at"`
-	IsVerified bool               `json:"is_verified"`
-	CreatedAt  time.Time          `json:"created_at"`
+	ID             bson.ObjectID `json:"id" bson:"_id"`
+	Email          string        `json:"email" bson:"email"`
+	OTPCode        string        `json:"otp_code" bson:"otp_code"`
+	ExpiresAt      time.Time     `json:"expires_at" bson:"expires_at"`
+	IsVerified     bool          `json:"is_verified" bson:"is_verified"`
+	CreatedAt      time.Time     `json:"created_at" bson:"created_at"`
+	ResetTokenHash string        `json:"-" bson:"reset_token_hash,omitempty"`
+	UsedAt         *time.Time    `json:"used_at,omitempty" bson:"used_at,omitempty"`
+	Attempts       int           `json:"attempts" bson:"attempts"`
+	LockedUntil    *time.Time    `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
 }
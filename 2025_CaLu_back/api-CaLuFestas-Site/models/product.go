@@ -1,17 +1,125 @@
 package models
 
 import (
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// Product é o documento gravado em "produtos". A serialização completa só é usada no painel administrativo; o catálogo
+// público devolve ProductView.
 type Product struct {
-	ID                  primitive.ObjectID `json:"_id,omitempty"`
-	Nome                string             `json:"nome"`
-	Categoria           string             `json:"categoria"`
-	Subcategoria        string             `json:"subcategoria"`
-	Quantidade          int                `json:"quantidade"`
-	QuantidadeEmLocacao int                `json:"quantidadeemlocacao"`
-	Preco               float64            `json:"preco"`
-	Descricao           string             `json:"descricao"`
-	Imagem              []string           `json:"imagem"`
+	ID                  bson.ObjectID `json:"_id,omitempty" bson:"_id"`
+	Nome                string        `json:"nome" bson:"nome"`
+	Categoria           string        `json:"categoria" bson:"categoria"`
+	Subcategoria        string        `json:"subcategoria" bson:"subcategoria"`
+	Quantidade          int           `json:"quantidade" bson:"quantidade"`
+	QuantidadeEmLocacao int           `json:"quantidadeemlocacao" bson:"quantidade_em_locacao"`
+	Preco               float64       `json:"preco" bson:"preco"`
+	Descricao           string        `json:"descricao" bson:"descricao"`
+	Imagem              []string      `json:"imagem" bson:"imagem"`
+	Arquivado           bool          `json:"arquivado" bson:"arquivado"`
+	ArquivadoEm         *time.Time    `json:"arquivadoem,omitempty" bson:"arquivado_em,omitempty"`
+}
+
+// ProductInput é o corpo aceito ao criar ou substituir um produto. Estoque em
+// locação e arquivamento são controlados pelo servidor.
+type ProductInput struct {
+	Nome         string   `json:"nome" binding:"required,max=200"`
+	Categoria    string   `json:"categoria"`
+	Subcategoria string   `json:"subcategoria"`
+	Quantidade   int      `json:"quantidade" binding:"min=0"`
+	Preco        float64  `json:"preco" binding:"min=0"`
+	Descricao    string   `json:"descricao"`
+	Imagem       []string `json:"imagem"`
+}
+
+// ProductView é o produto como aparece no catálogo público.
+type ProductView struct {
+	ID           bson.ObjectID `json:"_id"`
+	Nome         string        `json:"nome"`
+	Categoria    string        `json:"categoria"`
+	Subcategoria string        `json:"subcategoria"`
+	Quantidade   int           `json:"quantidade"`
+	Preco        float64       `json:"preco"`
+	Descricao    string        `json:"descricao"`
+	Imagem       []string      `json:"imagem"`
+}
+
+func (p Product) View() ProductView {
+	return ProductView{
+		ID:           p.ID,
+		Nome:         p.Nome,
+		Categoria:    p.Categoria,
+		Subcategoria: p.Subcategoria,
+		Quantidade:   p.Quantidade,
+		Preco:        p.Preco,
+		Descricao:    p.Descricao,
+		Imagem:       p.Imagem,
+	}
+}
+
+// Patch converte o corpo de um PUT em um patch que altera todos os campos
+// editáveis.
+func (in ProductInput) Patch() ProductPatch {
+	return ProductPatch{
+		Nome:         &in.Nome,
+		Categoria:    &in.Categoria,
+		Subcategoria: &in.Subcategoria,
+		Quantidade:   &in.Quantidade,
+		Preco:        &in.Preco,
+		Descricao:    &in.Descricao,
+		Imagem:       &in.Imagem,
+	}
+}
+
+// ProductPatch traz apenas os campos enviados em um PATCH; nil significa
+// "não alterar".
+type ProductPatch struct {
+	Nome         *string   `json:"nome"`
+	Categoria    *string   `json:"categoria"`
+	Subcategoria *string   `json:"subcategoria"`
+	Quantidade   *int      `json:"quantidade"`
+	Preco        *float64  `json:"preco"`
+	Descricao    *string   `json:"descricao"`
+	Imagem       *[]string `json:"imagem"`
+}
+
+func (p Product) Validar() error {
+	if p.Nome == "" {
+		return errors.New("nome é obrigatório")
+	}
+	if p.Quantidade < 0 {
+		return errors.New("quantidade não pode ser negativa")
+	}
+	if p.Preco < 0 {
+		return errors.New("preço não pode ser negativo")
+	}
+	return nil
+}
+
+// Aplicar copia para o produto os campos presentes no patch.
+func (patch ProductPatch) Aplicar(p *Product) {
+	if patch.Nome != nil {
+		p.Nome = *patch.Nome
+	}
+	if patch.Categoria != nil {
+		p.Categoria = *patch.Categoria
+	}
+	if patch.Subcategoria != nil {
+		p.Subcategoria = *patch.Subcategoria
+	}
+	if patch.Quantidade != nil {
+		p.Quantidade = *patch.Quantidade
+	}
+	if patch.Preco != nil {
+		p.Preco = *patch.Preco
+	}
+	if patch.Descricao != nil {
+		p.Descricao = *patch.Descricao
+	}
+	if patch.Imagem != nil {
+		p.Imagem = *patch.Imagem
+	}
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RefreshToken guarda apenas o hash do token. Todos os tokens gerados por
+// rotação a partir do mesmo login compartilham a Familia, o que permite
+// revogar a cadeia inteira se um token já usado aparecer de novo.
+type RefreshToken struct {
+	ID         bson.ObjectID `json:"_id" bson:"_id"`
+	Email      string        `json:"email" bson:"email"`
+	Hash       string        `json:"-" bson:"hash"`
+	Familia    string        `json:"familia" bson:"familia"`
+	CriadoEm   time.Time     `json:"criado_em" bson:"criado_em"`
+	ExpiraEm   time.Time     `json:"expira_em" bson:"expira_em"`
+	UsadoEm    *time.Time    `json:"usado_em,omitempty" bson:"usado_em,omitempty"`
+	RevogadoEm *time.Time    `json:"revogado_em,omitempty" bson:"revogado_em,omitempty"`
+}
+
+// TokenRevogado é um access token invalidado antes de expirar, identificado
+// pelo jti. O documento só precisa existir até ExpiraEm.
+type TokenRevogado struct {
+	JTI      string    `json:"jti" bson:"jti"`
+	ExpiraEm time.Time `json:"expira_em" bson:"expira_em"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
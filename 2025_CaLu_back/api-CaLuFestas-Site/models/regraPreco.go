@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type TipoRegraPreco string
+
+const (
+	// RegraDiaria substitui o preço por diária do produto.
+	RegraDiaria TipoRegraPreco = "diaria"
+	// RegraFimDeSemana aplica um percentual sobre as diárias de sábado e domingo.
+	RegraFimDeSemana TipoRegraPreco = "fim_de_semana"
+	// RegraFeriado aplica um percentual sobre as diárias que caem nas datas listadas.
+	RegraFeriado TipoRegraPreco = "feriado"
+	// RegraLongaDuracao dá desconto percentual a partir de MinDias diárias.
+	RegraLongaDuracao TipoRegraPreco = "longa_duracao"
+	// RegraPacote dá desconto percentual quando o pedido contém todos os componentes do kit.
+	RegraPacote TipoRegraPreco = "pacote"
+)
+
+const LayoutDataRegra = "2006-01-02"
+
+// EscopoRegra limita a quais produtos uma regra se aplica. Campos vazios não
+// restringem, então um escopo vazio vale para o catálogo inteiro.
+type EscopoRegra struct {
+	ProdutoID    bson.ObjectID `json:"produto_id,omitempty" bson:"produto_id,omitempty"`
+	Categoria    string        `json:"categoria,omitempty" bson:"categoria,omitempty"`
+	Subcategoria string        `json:"subcategoria,omitempty" bson:"subcategoria,omitempty"`
+}
+
+func (e EscopoRegra) Aplica(produto Product) bool {
+	if !e.ProdutoID.IsZero() && e.ProdutoID != produto.ID {
+		return false
+	}
+	if e.Categoria != "" && e.Categoria != produto.Categoria {
+		return false
+	}
+	if e.Subcategoria != "" && e.Subcategoria != produto.Subcategoria {
+		return false
+	}
+	return true
+}
+
+// Especificidade desempata regras de mesma prioridade: produto vence
+// subcategoria, que vence categoria, que vence o catálogo inteiro.
+func (e EscopoRegra) Especificidade() int {
+	switch {
+	case !e.ProdutoID.IsZero():
+		return 3
+	case e.Subcategoria != "":
+		return 2
+	case e.Categoria != "":
+		return 1
+	}
+	return 0
+}
+
+type ComponentePacote struct {
+	Escopo     EscopoRegra `json:"escopo" bson:"escopo"`
+	Quantidade int         `json:"quantidade" bson:"quantidade"`
+}
+
+type RegraPreco struct {
+	ID          bson.ObjectID      `json:"_id" bson:"_id"`
+	Nome        string             `json:"nome" bson:"nome"`
+	Tipo        TipoRegraPreco     `json:"tipo" bson:"tipo"`
+	Escopo      EscopoRegra        `json:"escopo" bson:"escopo"`
+	Preco       float64            `json:"preco,omitempty" bson:"preco,omitempty"`
+	Percentual  float64            `json:"percentual,omitempty" bson:"percentual,omitempty"`
+	MinDias     int                `json:"min_dias,omitempty" bson:"min_dias,omitempty"`
+	Datas       []string           `json:"datas,omitempty" bson:"datas,omitempty"`
+	Componentes []ComponentePacote `json:"componentes,omitempty" bson:"componentes,omitempty"`
+	Prioridade  int                `json:"prioridade" bson:"prioridade"`
+	Ativa       bool               `json:"ativa" bson:"ativa"`
+}
+
+func (r RegraPreco) Validar() error {
+	if r.Nome == "" {
+		return errors.New("nome é obrigatório")
+	}
+
+	switch r.Tipo {
+	case RegraDiaria:
+		if r.Preco <= 0 {
+			return errors.New("regra de diária precisa de preço positivo")
+		}
+	case RegraFimDeSemana:
+		if r.Percentual <= -100 || r.Percentual == 0 {
+			return errors.New("percentual inválido")
+		}
+	case RegraFeriado:
+		if r.Percentual <= -100 || r.Percentual == 0 {
+			return errors.New("percentual inválido")
+		}
+		if len(r.Datas) == 0 {
+			return errors.New("regra de feriado precisa de ao menos uma data")
+		}
+		for _, data := range r.Datas {
+			if _, err := time.Parse(LayoutDataRegra, data); err != nil {
+				return errors.New("data inválida, use aaaa-mm-dd: " + data)
+			}
+		}
+	case RegraLongaDuracao:
+		if r.MinDias < 2 {
+			return errors.New("regra de longa duração precisa de min_dias a partir de 2")
+		}
+		if r.Percentual <= 0 || r.Percentual > 100 {
+			return errors.New("desconto deve estar entre 0 e 100%")
+		}
+	case RegraPacote:
+		if len(r.Componentes) == 0 {
+			return errors.New("pacote precisa de componentes")
+		}
+		for _, componente := range r.Componentes {
+			if componente.Quantidade < 1 {
+				return errors.New("quantidade do componente deve ser positiva")
+			}
+		}
+		if r.Percentual <= 0 || r.Percentual > 100 {
+			return errors.New("desconto deve estar entre 0 e 100%")
+		}
+	default:
+		return errors.New("tipo de regra desconhecido: " + string(r.Tipo))
+	}
+
+	return nil
+}
+
+// TemData informa se o dia (no formato aaaa-mm-dd) está entre as datas da regra.
+func (r RegraPreco) TemData(dia string) bool {
+	for _, data := range r.Datas {
+		if data == dia {
+			return true
+		}
+	}
+	return false
+}
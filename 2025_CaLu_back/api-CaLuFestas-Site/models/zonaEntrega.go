@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FaixaCEP é um intervalo fechado de CEPs, ambos com 8 dígitos.
+type FaixaCEP struct {
+	Inicio string `json:"inicio" bson:"inicio"`
+	Fim    string `json:"fim" bson:"fim"`
+}
+
+func (f FaixaCEP) Contem(cep string) bool {
+	// Com 8 dígitos a ordem das strings é a ordem numérica
+	return f.Inicio <= cep && cep <= f.Fim
+}
+
+// ZonaEntrega define quanto custa levar e buscar o material em uma região.
+// Um endereço pertence à zona se o CEP cair em uma das faixas ou se o bairro
+// estiver na lista; Cidade e UF, quando preenchidas, restringem os bairros.
+type ZonaEntrega struct {
+	ID           bson.ObjectID `json:"_id" bson:"_id"`
+	Nome         string        `json:"nome" bson:"nome"`
+	FaixasCEP    []FaixaCEP    `json:"faixas_cep,omitempty" bson:"faixas_cep,omitempty"`
+	Bairros      []string      `json:"bairros,omitempty" bson:"bairros,omitempty"`
+	Cidade       string        `json:"cidade,omitempty" bson:"cidade,omitempty"`
+	UF           string        `json:"uf,omitempty" bson:"uf,omitempty"`
+	TaxaEntrega  float64       `json:"taxa_entrega" bson:"taxa_entrega"`
+	TaxaRetirada float64       `json:"taxa_retirada" bson:"taxa_retirada"`
+	Prioridade   int           `json:"prioridade" bson:"prioridade"`
+	Ativa        bool          `json:"ativa" bson:"ativa"`
+}
+
+// Normalizar deixa CEPs só com dígitos e a UF em maiúsculas. Deve ser
+// chamado antes de Validar.
+func (z *ZonaEntrega) Normalizar() {
+	z.Nome = strings.TrimSpace(z.Nome)
+	z.Cidade = strings.TrimSpace(z.Cidade)
+	z.UF = strings.ToUpper(strings.TrimSpace(z.UF))
+	for i, faixa := range z.FaixasCEP {
+		if cep, err := NormalizarCEP(faixa.Inicio); err == nil {
+			z.FaixasCEP[i].Inicio = cep
+		}
+		if cep, err := NormalizarCEP(faixa.Fim); err == nil {
+			z.FaixasCEP[i].Fim = cep
+		}
+	}
+	for i, bairro := range z.Bairros {
+		z.Bairros[i] = strings.TrimSpace(bairro)
+	}
+}
+
+func (z ZonaEntrega) Validar() error {
+	if z.Nome == "" {
+		return errors.New("nome é obrigatório")
+	}
+	if len(z.FaixasCEP) == 0 && len(z.Bairros) == 0 {
+		return errors.New("a zona precisa de faixas de CEP ou bairros")
+	}
+	for _, faixa := range z.FaixasCEP {
+		if _, err := NormalizarCEP(faixa.Inicio); err != nil {
+			return errors.New("CEP inicial inválido: " + faixa.Inicio)
+		}
+		if _, err := NormalizarCEP(faixa.Fim); err != nil {
+			return errors.New("CEP final inválido: " + faixa.Fim)
+		}
+		if faixa.Inicio > faixa.Fim {
+			return errors.New("faixa de CEP invertida: " + faixa.Inicio + " a " + faixa.Fim)
+		}
+	}
+	if z.UF != "" && !ufs[z.UF] {
+		return errors.New("UF inválida")
+	}
+	if z.TaxaEntrega < 0 || z.TaxaRetirada < 0 {
+		return errors.New("taxas não podem ser negativas")
+	}
+	return nil
+}
+
+// Atende diz se o endereço, já normalizado, pertence à zona. Bairro, cidade
+// e UF devem vir da consulta do CEP, não do que o cliente digitou; sem bairro
+// conhecido, só as faixas de CEP valem.
+func (z ZonaEntrega) Atende(endereco Endereco) bool {
+	for _, faixa := range z.FaixasCEP {
+		if faixa.Contem(endereco.CEP) {
+			return true
+		}
+	}
+
+	if z.Cidade != "" && !strings.EqualFold(z.Cidade, endereco.Cidade) {
+		return false
+	}
+	if z.UF != "" && z.UF != endereco.UF {
+		return false
+	}
+	if endereco.Bairro == "" {
+		return false
+	}
+	for _, bairro := range z.Bairros {
+		if strings.EqualFold(bairro, endereco.Bairro) {
+			return true
+		}
+	}
+	return false
+}
+
+// Frete é a linha de entrega e retirada de um orçamento.
+type Frete struct {
+	ZonaID   bson.ObjectID `json:"zona_id" bson:"zona_id"`
+	Zona     string        `json:"zona" bson:"zona"`
+	Entrega  float64       `json:"entrega" bson:"entrega"`
+	Retirada float64       `json:"retirada" bson:"retirada"`
+	Total    float64       `json:"total" bson:"total"`
+}
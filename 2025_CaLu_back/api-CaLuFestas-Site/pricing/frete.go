@@ -0,0 +1,46 @@
+package pricing
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+)
+
+var ErrForaDaArea = errors.New("endereço fora da área de entrega")
+
+// CalcularFrete escolhe, entre as zonas que atendem o endereço, a de maior
+// prioridade; no empate vale a de menor taxa total.
+func CalcularFrete(endereco models.Endereco, zonas []models.ZonaEntrega) (models.Frete, error) {
+	var candidatas []models.ZonaEntrega
+	for _, zona := range zonas {
+		if zona.Atende(endereco) {
+			candidatas = append(candidatas, zona)
+		}
+	}
+	if len(candidatas) == 0 {
+		return models.Frete{}, ErrForaDaArea
+	}
+
+	sort.SliceStable(candidatas, func(i, j int) bool {
+		if candidatas[i].Prioridade != candidatas[j].Prioridade {
+			return candidatas[i].Prioridade > candidatas[j].Prioridade
+		}
+		return candidatas[i].TaxaEntrega+candidatas[i].TaxaRetirada < candidatas[j].TaxaEntrega+candidatas[j].TaxaRetirada
+	})
+
+	zona := candidatas[0]
+	return models.Frete{
+		ZonaID:   zona.ID,
+		Zona:     zona.Nome,
+		Entrega:  zona.TaxaEntrega,
+		Retirada: zona.TaxaRetirada,
+		Total:    models.Arredondar(zona.TaxaEntrega + zona.TaxaRetirada),
+	}, nil
+}
+
+// AplicarFrete acrescenta o frete ao orçamento como uma linha à parte.
+func AplicarFrete(orcamento *models.Orcamento, frete models.Frete) {
+	orcamento.Frete = &frete
+	orcamento.Total = models.Arredondar(orcamento.Total + frete.Total)
+}
@@ -0,0 +1,197 @@
+// Package pricing calcula o valor de uma locação aplicando as regras de preço
+// cadastradas sobre o preço base de cada produto.
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+)
+
+// Linha é um produto do pedido com a quantidade desejada.
+type Linha struct {
+	Produto    models.Product
+	Quantidade int
+}
+
+// Calcular monta o orçamento de [inicio, fim). Para cada produto vale no
+// máximo uma regra de cada tipo: a de maior prioridade e, no empate, a de
+// escopo mais específico. Pacotes são avaliados sobre o pedido inteiro e
+// entram como ajustes do orçamento, não dos itens.
+func Calcular(inicio, fim time.Time, linhas []Linha, regras []models.RegraPreco) models.Orcamento {
+	regras = ordenar(regras)
+	dias := models.DiasLocacao(inicio, fim)
+
+	orcamento := models.Orcamento{Dias: dias, Itens: []models.ItemOrcamento{}}
+	for _, linha := range linhas {
+		item := calcularItem(linha, inicio, dias, regras)
+		orcamento.Itens = append(orcamento.Itens, item)
+		orcamento.Total += item.Subtotal
+	}
+
+	// Unidades ainda livres para pacotes; uma unidade entra em um kit só
+	restantes := make([]int, len(orcamento.Itens))
+	for i, item := range orcamento.Itens {
+		restantes[i] = item.Quantidade
+	}
+	for _, regra := range regras {
+		if regra.Tipo != models.RegraPacote {
+			continue
+		}
+		if ajuste, ok := aplicarPacote(regra, linhas, orcamento.Itens, restantes); ok {
+			orcamento.Ajustes = append(orcamento.Ajustes, ajuste)
+			orcamento.Total += ajuste.Valor
+		}
+	}
+
+	orcamento.Total = models.Arredondar(orcamento.Total)
+	return orcamento
+}
+
+func calcularItem(linha Linha, inicio time.Time, dias int, regras []models.RegraPreco) models.ItemOrcamento {
+	produto := linha.Produto
+	quantidade := float64(linha.Quantidade)
+
+	item := models.ItemOrcamento{
+		ProdutoID:     produto.ID,
+		Nome:          produto.Nome,
+		PrecoUnitario: produto.Preco,
+		Quantidade:    linha.Quantidade,
+		Dias:          dias,
+	}
+
+	if regra, ok := primeira(regras, models.RegraDiaria, produto); ok {
+		item.PrecoUnitario = regra.Preco
+	}
+	base := item.PrecoUnitario * quantidade * float64(dias)
+
+	fimDeSemana, temFimDeSemana := primeira(regras, models.RegraFimDeSemana, produto)
+	feriado, temFeriado := primeira(regras, models.RegraFeriado, produto)
+
+	var acrescimoFimDeSemana, acrescimoFeriado float64
+	for d := 0; d < dias; d++ {
+		dia := inicio.AddDate(0, 0, d)
+		diaria := item.PrecoUnitario * quantidade
+		if temFimDeSemana && (dia.Weekday() == time.Saturday || dia.Weekday() == time.Sunday) {
+			acrescimoFimDeSemana += diaria * fimDeSemana.Percentual / 100
+		}
+		if temFeriado && feriado.TemData(dia.Format(models.LayoutDataRegra)) {
+			acrescimoFeriado += diaria * feriado.Percentual / 100
+		}
+	}
+
+	subtotal := base
+	if acrescimoFimDeSemana != 0 {
+		subtotal += adicionarAjuste(&item, fimDeSemana, "Diárias de fim de semana", acrescimoFimDeSemana)
+	}
+	if acrescimoFeriado != 0 {
+		subtotal += adicionarAjuste(&item, feriado, "Diárias em feriado", acrescimoFeriado)
+	}
+
+	for _, regra := range regras {
+		if regra.Tipo == models.RegraLongaDuracao && dias >= regra.MinDias && regra.Escopo.Aplica(produto) {
+			descricao := fmt.Sprintf("Locação a partir de %d dias", regra.MinDias)
+			subtotal += adicionarAjuste(&item, regra, descricao, -subtotal*regra.Percentual/100)
+			break
+		}
+	}
+
+	item.Subtotal = models.Arredondar(subtotal)
+	return item
+}
+
+// aplicarPacote conta quantos kits completos as unidades ainda livres formam
+// e desconta o percentual da regra sobre o valor das unidades que compõem
+// esses kits, tirando-as de restantes. Cada linha conta para um único
+// componente do kit: o de escopo mais específico que a aceita.
+func aplicarPacote(regra models.RegraPreco, linhas []Linha, itens []models.ItemOrcamento, restantes []int) (models.AjusteOrcamento, bool) {
+	componenteDa := make([]int, len(linhas))
+	disponiveis := make([]int, len(regra.Componentes))
+	for i, linha := range linhas {
+		componenteDa[i] = -1
+		for c, componente := range regra.Componentes {
+			if !componente.Escopo.Aplica(linha.Produto) {
+				continue
+			}
+			if componenteDa[i] == -1 || componente.Escopo.Especificidade() > regra.Componentes[componenteDa[i]].Escopo.Especificidade() {
+				componenteDa[i] = c
+			}
+		}
+		if componenteDa[i] != -1 {
+			disponiveis[componenteDa[i]] += restantes[i]
+		}
+	}
+
+	kits := -1
+	for c, componente := range regra.Componentes {
+		if k := disponiveis[c] / componente.Quantidade; kits == -1 || k < kits {
+			kits = k
+		}
+	}
+	if kits <= 0 {
+		return models.AjusteOrcamento{}, false
+	}
+
+	valor := 0.0
+	for c, componente := range regra.Componentes {
+		faltam := componente.Quantidade * kits
+		for i, item := range itens {
+			if faltam == 0 {
+				break
+			}
+			if componenteDa[i] != c || restantes[i] == 0 {
+				continue
+			}
+			usadas := min(faltam, restantes[i])
+			restantes[i] -= usadas
+			faltam -= usadas
+			valor += item.Subtotal / float64(item.Quantidade) * float64(usadas)
+		}
+	}
+
+	return models.AjusteOrcamento{
+		Regra:     regra.Nome,
+		Tipo:      regra.Tipo,
+		Descricao: fmt.Sprintf("%d kit(s) %s", kits, regra.Nome),
+		Valor:     models.Arredondar(-valor * regra.Percentual / 100),
+	}, true
+}
+
+// ordenar devolve as regras ativas da mais prioritária para a menos
+// prioritária, desempatando pela especificidade do escopo.
+func ordenar(regras []models.RegraPreco) []models.RegraPreco {
+	ativas := make([]models.RegraPreco, 0, len(regras))
+	for _, regra := range regras {
+		if regra.Ativa {
+			ativas = append(ativas, regra)
+		}
+	}
+	sort.SliceStable(ativas, func(i, j int) bool {
+		if ativas[i].Prioridade != ativas[j].Prioridade {
+			return ativas[i].Prioridade > ativas[j].Prioridade
+		}
+		return ativas[i].Escopo.Especificidade() > ativas[j].Escopo.Especificidade()
+	})
+	return ativas
+}
+
+func primeira(regras []models.RegraPreco, tipo models.TipoRegraPreco, produto models.Product) (models.RegraPreco, bool) {
+	for _, regra := range regras {
+		if regra.Tipo == tipo && regra.Escopo.Aplica(produto) {
+			return regra, true
+		}
+	}
+	return models.RegraPreco{}, false
+}
+
+func adicionarAjuste(item *models.ItemOrcamento, regra models.RegraPreco, descricao string, valor float64) float64 {
+	item.Ajustes = append(item.Ajustes, models.AjusteOrcamento{
+		Regra:     regra.Nome,
+		Tipo:      regra.Tipo,
+		Descricao: descricao,
+		Valor:     models.Arredondar(valor),
+	})
+	return valor
+}
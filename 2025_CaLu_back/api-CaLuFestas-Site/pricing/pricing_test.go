@@ -0,0 +1,259 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Uma terça-feira, para não cair em regra de fim de semana
+var (
+	inicio = time.Date(2030, 1, 8, 0, 0, 0, 0, time.UTC)
+	fim    = inicio.AddDate(0, 0, 1)
+)
+
+var (
+	mesa    = models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Categoria: "Mesas", Preco: 20}
+	mesaVip = models.Product{ID: bson.NewObjectID(), Nome: "Mesa VIP", Categoria: "Mesas", Preco: 40}
+	cadeira = models.Product{ID: bson.NewObjectID(), Nome: "Cadeira", Categoria: "Cadeiras", Preco: 5}
+)
+
+func kit(nome string, percentual float64, prioridade int, componentes ...models.ComponentePacote) models.RegraPreco {
+	return models.RegraPreco{
+		ID:          bson.NewObjectID(),
+		Nome:        nome,
+		Tipo:        models.RegraPacote,
+		Percentual:  percentual,
+		Componentes: componentes,
+		Prioridade:  prioridade,
+		Ativa:       true,
+	}
+}
+
+func categoria(nome string, quantidade int) models.ComponentePacote {
+	return models.ComponentePacote{Escopo: models.EscopoRegra{Categoria: nome}, Quantidade: quantidade}
+}
+
+func produto(p models.Product, quantidade int) models.ComponentePacote {
+	return models.ComponentePacote{Escopo: models.EscopoRegra{ProdutoID: p.ID}, Quantidade: quantidade}
+}
+
+func TestPacoteDescontaKitsCompletos(t *testing.T) {
+	regras := []models.RegraPreco{kit("Mesa com 4 cadeiras", 10, 0, categoria("Mesas", 1), categoria("Cadeiras", 4))}
+	linhas := []Linha{{Produto: mesa, Quantidade: 2}, {Produto: cadeira, Quantidade: 9}}
+
+	orcamento := Calcular(inicio, fim, linhas, regras)
+
+	// 2 kits: (2x20 + 8x5) x 10% = 8; a nona cadeira fica fora
+	if len(orcamento.Ajustes) != 1 || orcamento.Ajustes[0].Valor != -8 {
+		t.Fatalf("ajustes = %+v, esperado um desconto de 8", orcamento.Ajustes)
+	}
+	if orcamento.Total != 77 {
+		t.Fatalf("total = %v, esperado 77", orcamento.Total)
+	}
+}
+
+func TestPacotesNaoDescontamAsMesmasUnidades(t *testing.T) {
+	regras := []models.RegraPreco{
+		kit("Kit principal", 10, 1, categoria("Mesas", 1), categoria("Cadeiras", 4)),
+		kit("Kit secundário", 5, 0, categoria("Mesas", 1), categoria("Cadeiras", 4)),
+	}
+	linhas := []Linha{{Produto: mesa, Quantidade: 1}, {Produto: cadeira, Quantidade: 4}}
+
+	orcamento := Calcular(inicio, fim, linhas, regras)
+
+	if len(orcamento.Ajustes) != 1 || orcamento.Ajustes[0].Regra != "Kit principal" {
+		t.Fatalf("ajustes = %+v, esperado só o kit principal", orcamento.Ajustes)
+	}
+	if orcamento.Total != 36 {
+		t.Fatalf("total = %v, esperado 36 (40 - 10%%)", orcamento.Total)
+	}
+}
+
+func TestPacoteUsaUnidadesQueSobraramDeOutroKit(t *testing.T) {
+	regras := []models.RegraPreco{
+		kit("Mesa com 8 cadeiras", 10, 1, categoria("Mesas", 1), categoria("Cadeiras", 8)),
+		kit("Mesa com 4 cadeiras", 50, 0, categoria("Mesas", 1), categoria("Cadeiras", 4)),
+	}
+	linhas := []Linha{{Produto: mesa, Quantidade: 2}, {Produto: cadeira, Quantidade: 12}}
+
+	orcamento := Calcular(inicio, fim, linhas, regras)
+
+	// O primeiro kit leva 1 mesa e 8 cadeiras (60 x 10% = 6); o segundo só
+	// forma 1 kit com o que sobrou (40 x 50% = 20)
+	if len(orcamento.Ajustes) != 2 || orcamento.Ajustes[0].Valor != -6 || orcamento.Ajustes[1].Valor != -20 {
+		t.Fatalf("ajustes = %+v, esperado -6 e -20", orcamento.Ajustes)
+	}
+	if orcamento.Total != 74 {
+		t.Fatalf("total = %v, esperado 74", orcamento.Total)
+	}
+}
+
+func TestLinhaContaParaUmComponenteSo(t *testing.T) {
+	// A Mesa VIP casa com os dois componentes; conta só para o mais específico
+	regras := []models.RegraPreco{kit("Mesa VIP com mesa de apoio", 20, 0, categoria("Mesas", 1), produto(mesaVip, 1))}
+
+	sozinha := Calcular(inicio, fim, []Linha{{Produto: mesaVip, Quantidade: 1}}, regras)
+	if len(sozinha.Ajustes) != 0 {
+		t.Fatalf("uma unidade formou o kit inteiro: %+v", sozinha.Ajustes)
+	}
+
+	duas := Calcular(inicio, fim, []Linha{{Produto: mesaVip, Quantidade: 2}}, regras)
+	if len(duas.Ajustes) != 0 {
+		t.Fatalf("a mesma linha contou para os dois componentes: %+v", duas.Ajustes)
+	}
+
+	completo := Calcular(inicio, fim, []Linha{{Produto: mesaVip, Quantidade: 1}, {Produto: mesa, Quantidade: 1}}, regras)
+	if len(completo.Ajustes) != 1 || completo.Ajustes[0].Valor != -12 {
+		t.Fatalf("ajustes = %+v, esperado desconto de 12 ((40+20) x 20%%)", completo.Ajustes)
+	}
+}
+
+func regra(tipo models.TipoRegraPreco, nome string, prioridade int, escopo models.EscopoRegra) models.RegraPreco {
+	return models.RegraPreco{ID: bson.NewObjectID(), Nome: nome, Tipo: tipo, Escopo: escopo, Prioridade: prioridade, Ativa: true}
+}
+
+func dias(de time.Time, n int) (time.Time, time.Time) {
+	return de, de.AddDate(0, 0, n)
+}
+
+// sexta-feira, 11/01/2030
+var sexta = time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC)
+
+func TestDiariaSubstituiPrecoDoProduto(t *testing.T) {
+	diaria := regra(models.RegraDiaria, "Mesa promocional", 0, models.EscopoRegra{ProdutoID: mesa.ID})
+	diaria.Preco = 15
+	de, ate := dias(inicio, 2)
+
+	orcamento := Calcular(de, ate, []Linha{{Produto: mesa, Quantidade: 2}, {Produto: cadeira, Quantidade: 4}}, []models.RegraPreco{diaria})
+
+	if orcamento.Itens[0].PrecoUnitario != 15 || orcamento.Itens[0].Subtotal != 60 {
+		t.Fatalf("mesa = %+v, esperado 2 x 15 x 2 dias", orcamento.Itens[0])
+	}
+	if orcamento.Itens[1].PrecoUnitario != 5 || orcamento.Itens[1].Subtotal != 40 {
+		t.Fatalf("cadeira = %+v, a regra é só da mesa", orcamento.Itens[1])
+	}
+	if orcamento.Total != 100 {
+		t.Fatalf("total = %v, esperado 100", orcamento.Total)
+	}
+}
+
+func TestFimDeSemanaSoNasDiariasDeSabadoEDomingo(t *testing.T) {
+	fds := regra(models.RegraFimDeSemana, "Fim de semana", 0, models.EscopoRegra{})
+	fds.Percentual = 50
+
+	// sexta, sábado e domingo
+	de, ate := dias(sexta, 3)
+	item := Calcular(de, ate, []Linha{{Produto: mesa, Quantidade: 1}}, []models.RegraPreco{fds}).Itens[0]
+	if len(item.Ajustes) != 1 || item.Ajustes[0].Valor != 20 || item.Subtotal != 80 {
+		t.Fatalf("item = %+v, esperado 60 + 50%% de 2 diárias", item)
+	}
+
+	// terça e quarta
+	de, ate = dias(inicio, 2)
+	item = Calcular(de, ate, []Linha{{Produto: mesa, Quantidade: 1}}, []models.RegraPreco{fds}).Itens[0]
+	if len(item.Ajustes) != 0 || item.Subtotal != 40 {
+		t.Fatalf("item = %+v, sem acréscimo durante a semana", item)
+	}
+}
+
+func TestFeriadoSoNasDatasListadas(t *testing.T) {
+	feriado := regra(models.RegraFeriado, "Aniversário da cidade", 0, models.EscopoRegra{Categoria: "Mesas"})
+	feriado.Percentual = 100
+	feriado.Datas = []string{"2030-01-09", "2030-03-01"}
+
+	de, ate := dias(inicio, 3)
+	orcamento := Calcular(de, ate, []Linha{{Produto: mesa, Quantidade: 2}, {Produto: cadeira, Quantidade: 1}}, []models.RegraPreco{feriado})
+
+	// Só a diária de 09/01 dobra, e só para a categoria Mesas
+	if item := orcamento.Itens[0]; len(item.Ajustes) != 1 || item.Ajustes[0].Valor != 40 || item.Subtotal != 160 {
+		t.Fatalf("mesa = %+v, esperado 120 + 40", item)
+	}
+	if item := orcamento.Itens[1]; len(item.Ajustes) != 0 || item.Subtotal != 15 {
+		t.Fatalf("cadeira = %+v, esperado sem acréscimo", item)
+	}
+}
+
+func TestLongaDuracaoAPartirDoMinimo(t *testing.T) {
+	semana := regra(models.RegraLongaDuracao, "Uma semana", 1, models.EscopoRegra{})
+	semana.Percentual, semana.MinDias = 20, 7
+	tresDias := regra(models.RegraLongaDuracao, "Três dias", 0, models.EscopoRegra{})
+	tresDias.Percentual, tresDias.MinDias = 10, 3
+	regras := []models.RegraPreco{tresDias, semana}
+
+	casos := []struct {
+		dias     int
+		subtotal float64
+		regra    string
+	}{
+		{2, 40, ""},
+		{3, 54, "Três dias"},
+		{7, 112, "Uma semana"},
+	}
+	for _, caso := range casos {
+		de, ate := dias(inicio, caso.dias)
+		item := Calcular(de, ate, []Linha{{Produto: mesa, Quantidade: 1}}, regras).Itens[0]
+		aplicada := ""
+		if len(item.Ajustes) > 0 {
+			aplicada = item.Ajustes[0].Regra
+		}
+		if item.Subtotal != caso.subtotal || aplicada != caso.regra || len(item.Ajustes) > 1 {
+			t.Errorf("%d dias: item = %+v, esperado %v com %q", caso.dias, item, caso.subtotal, caso.regra)
+		}
+	}
+}
+
+func TestLongaDuracaoIncideSobreOsAcrescimos(t *testing.T) {
+	fds := regra(models.RegraFimDeSemana, "Fim de semana", 0, models.EscopoRegra{})
+	fds.Percentual = 50
+	longa := regra(models.RegraLongaDuracao, "Três dias", 0, models.EscopoRegra{})
+	longa.Percentual, longa.MinDias = 10, 3
+
+	de, ate := dias(sexta, 3)
+	item := Calcular(de, ate, []Linha{{Produto: mesa, Quantidade: 1}}, []models.RegraPreco{longa, fds}).Itens[0]
+	if item.Subtotal != 72 {
+		t.Fatalf("item = %+v, esperado (60 + 20) - 10%%", item)
+	}
+}
+
+func TestRegraVencedora(t *testing.T) {
+	diaria := func(nome string, prioridade int, escopo models.EscopoRegra, preco float64) models.RegraPreco {
+		r := regra(models.RegraDiaria, nome, prioridade, escopo)
+		r.Preco = preco
+		return r
+	}
+	geral := diaria("Catálogo", 0, models.EscopoRegra{}, 10)
+	categoriaMesas := diaria("Mesas", 0, models.EscopoRegra{Categoria: "Mesas"}, 12)
+	daMesa := diaria("Mesa", 0, models.EscopoRegra{ProdutoID: mesa.ID}, 15)
+	urgente := diaria("Promoção", 5, models.EscopoRegra{}, 8)
+	inativa := diaria("Desligada", 9, models.EscopoRegra{ProdutoID: mesa.ID}, 1)
+	inativa.Ativa = false
+
+	casos := []struct {
+		nome   string
+		regras []models.RegraPreco
+		preco  float64
+	}{
+		{"produto vence o catálogo no empate", []models.RegraPreco{geral, daMesa}, 15},
+		{"produto vence a categoria no empate", []models.RegraPreco{categoriaMesas, daMesa}, 15},
+		{"categoria vence o catálogo no empate", []models.RegraPreco{geral, categoriaMesas}, 12},
+		{"prioridade vence a especificidade", []models.RegraPreco{daMesa, urgente, categoriaMesas}, 8},
+		{"regra inativa não conta", []models.RegraPreco{inativa, geral}, 10},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			item := Calcular(inicio, fim, []Linha{{Produto: mesa, Quantidade: 1}}, caso.regras).Itens[0]
+			if item.PrecoUnitario != caso.preco {
+				t.Fatalf("preço = %v, esperado %v", item.PrecoUnitario, caso.preco)
+			}
+		})
+	}
+
+	// A cadeira não é da categoria nem é o produto: só a regra geral vale
+	item := Calcular(inicio, fim, []Linha{{Produto: cadeira, Quantidade: 1}}, []models.RegraPreco{daMesa, categoriaMesas, geral}).Itens[0]
+	if item.PrecoUnitario != 10 {
+		t.Fatalf("cadeira = %v, esperado 10", item.PrecoUnitario)
+	}
+}
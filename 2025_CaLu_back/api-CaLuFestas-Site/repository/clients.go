@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ClientFilter restringe List. Busca procura por parte do nome ou do e-mail,
+// sem diferenciar maiúsculas. Status "ativo" inclui contas antigas sem status.
+type ClientFilter struct {
+	Busca  string
+	Cargo  string
+	Status string
+}
+
+// ClientChanges traz os campos a alterar; nil significa "não alterar".
+type ClientChanges struct {
+	Nome      *string
+	Senha     *string
+	Cargo     *string
+	Status    *string
+	Telefone  *string
+	Documento *string
+	Enderecos *[]models.EnderecoSalvo
+}
+
+type ClientRepository interface {
+	FindByID(ctx context.Context, id bson.ObjectID) (models.Client, error)
+	FindByEmail(ctx context.Context, email string) (models.Client, error)
+	// List ordena por nome e devolve também o total sem paginação.
+	List(ctx context.Context, filtro ClientFilter, page Page) ([]models.Client, int64, error)
+	// Insert devolve ErrDuplicate se o e-mail já estiver cadastrado.
+	Insert(ctx context.Context, client models.Client) error
+	// Update devolve o cliente já alterado.
+	Update(ctx context.Context, id bson.ObjectID, changes ClientChanges) (models.Client, error)
+	// SetStatusIf só troca o status se ele ainda for atual, e diz se trocou.
+	SetStatusIf(ctx context.Context, id bson.ObjectID, atual, novo string) (bool, error)
+	Delete(ctx context.Context, id bson.ObjectID) error
+}
+
+// Garante que as duas implementações satisfazem ClientRepository
+var (
+	_ ClientRepository = (*MongoClients)(nil)
+	_ ClientRepository = (*MemoryClients)(nil)
+)
+
+// MongoClients guarda os clientes na coleção "clients".
+type MongoClients struct {
+	collection *mongo.Collection
+}
+
+func NewMongoClients(db *mongo.Database) *MongoClients {
+	return &MongoClients{collection: db.Collection("clients")}
+}
+
+func (r *MongoClients) findOne(ctx context.Context, filter bson.M) (models.Client, error) {
+	var client models.Client
+	err := r.collection.FindOne(ctx, filter).Decode(&client)
+	if err == mongo.ErrNoDocuments {
+		return client, ErrNotFound
+	}
+	return client, err
+}
+
+func (r *MongoClients) FindByID(ctx context.Context, id bson.ObjectID) (models.Client, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoClients) FindByEmail(ctx context.Context, email string) (models.Client, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *MongoClients) List(ctx context.Context, filtro ClientFilter, page Page) ([]models.Client, int64, error) {
+	filter := bson.M{}
+	if filtro.Busca != "" {
+		busca := bson.M{"$regex": regexp.QuoteMeta(filtro.Busca), "$options": "i"}
+		filter["$or"] = []bson.M{{"nome": busca}, {"email": busca}}
+	}
+	if filtro.Cargo != "" {
+		filter["cargo"] = filtro.Cargo
+	}
+	switch filtro.Status {
+	case "":
+	case models.StatusAtivo:
+		filter["status"] = bson.M{"$nin": []string{models.StatusPendente, models.StatusDesativado}}
+	default:
+		filter["status"] = filtro.Status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.M{"nome": 1}).SetSkip(page.Skip)
+	if page.Limit > 0 {
+		opts.SetLimit(page.Limit)
+	}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	clients := []models.Client{}
+	if err := cursor.All(ctx, &clients); err != nil {
+		return nil, 0, err
+	}
+	return clients, total, nil
+}
+
+func (r *MongoClients) Insert(ctx context.Context, client models.Client) error {
+	_, err := r.collection.InsertOne(ctx, client)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *MongoClients) Update(ctx context.Context, id bson.ObjectID, changes ClientChanges) (models.Client, error) {
+	set := bson.M{}
+	if changes.Nome != nil {
+		set["nome"] = *changes.Nome
+	}
+	if changes.Senha != nil {
+		set["senha"] = *changes.Senha
+	}
+	if changes.Cargo != nil {
+		set["cargo"] = *changes.Cargo
+	}
+	if changes.Status != nil {
+		set["status"] = *changes.Status
+	}
+	if changes.Telefone != nil {
+		set["telefone"] = *changes.Telefone
+	}
+	if changes.Documento != nil {
+		set["documento"] = *changes.Documento
+	}
+	if changes.Enderecos != nil {
+		set["enderecos"] = *changes.Enderecos
+	}
+	if len(set) == 0 {
+		return r.FindByID(ctx, id)
+	}
+
+	var client models.Client
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&client)
+	if err == mongo.ErrNoDocuments {
+		return client, ErrNotFound
+	}
+	return client, err
+}
+
+func (r *MongoClients) SetStatusIf(ctx context.Context, id bson.ObjectID, atual, novo string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": atual}, bson.M{"$set": bson.M{"status": novo}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoClients) Delete(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MemoryClients guarda os clientes em um mapa, com a mesma semântica de
+// MongoClients, incluindo a unicidade do e-mail.
+type MemoryClients struct {
+	mu      sync.Mutex
+	clients map[bson.ObjectID]models.Client
+}
+
+func NewMemoryClients(clients ...models.Client) *MemoryClients {
+	r := &MemoryClients{clients: map[bson.ObjectID]models.Client{}}
+	for _, client := range clients {
+		r.clients[client.ID] = copiarCliente(client)
+	}
+	return r
+}
+
+func (r *MemoryClients) FindByID(ctx context.Context, id bson.ObjectID) (models.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[id]
+	if !ok {
+		return models.Client{}, ErrNotFound
+	}
+	return copiarCliente(client), nil
+}
+
+func (r *MemoryClients) FindByEmail(ctx context.Context, email string) (models.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, client := range r.clients {
+		if client.Email == email {
+			return copiarCliente(client), nil
+		}
+	}
+	return models.Client{}, ErrNotFound
+}
+
+func (r *MemoryClients) List(ctx context.Context, filtro ClientFilter, page Page) ([]models.Client, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	busca := strings.ToLower(filtro.Busca)
+	clients := []models.Client{}
+	for _, client := range r.clients {
+		if busca != "" && !strings.Contains(strings.ToLower(client.Nome), busca) && !strings.Contains(strings.ToLower(client.Email), busca) {
+			continue
+		}
+		if filtro.Cargo != "" && client.Cargo != filtro.Cargo {
+			continue
+		}
+		switch filtro.Status {
+		case "":
+		case models.StatusAtivo:
+			if client.Status == models.StatusPendente || client.Status == models.StatusDesativado {
+				continue
+			}
+		default:
+			if client.Status != filtro.Status {
+				continue
+			}
+		}
+		clients = append(clients, copiarCliente(client))
+	}
+
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Nome < clients[j].Nome })
+	return aplicar(clients, page), int64(len(clients)), nil
+}
+
+func (r *MemoryClients) Insert(ctx context.Context, client models.Client) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existente := range r.clients {
+		if existente.Email == client.Email || existente.ID == client.ID {
+			return ErrDuplicate
+		}
+	}
+	r.clients[client.ID] = copiarCliente(client)
+	return nil
+}
+
+func (r *MemoryClients) Update(ctx context.Context, id bson.ObjectID, changes ClientChanges) (models.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[id]
+	if !ok {
+		return models.Client{}, ErrNotFound
+	}
+	if changes.Nome != nil {
+		client.Nome = *changes.Nome
+	}
+	if changes.Senha != nil {
+		client.Senha = *changes.Senha
+	}
+	if changes.Cargo != nil {
+		client.Cargo = *changes.Cargo
+	}
+	if changes.Status != nil {
+		client.Status = *changes.Status
+	}
+	if changes.Telefone != nil {
+		client.Telefone = *changes.Telefone
+	}
+	if changes.Documento != nil {
+		client.Documento = *changes.Documento
+	}
+	if changes.Enderecos != nil {
+		client.Enderecos = *changes.Enderecos
+	}
+	client = copiarCliente(client)
+	r.clients[id] = client
+	return copiarCliente(client), nil
+}
+
+func (r *MemoryClients) SetStatusIf(ctx context.Context, id bson.ObjectID, atual, novo string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[id]
+	if !ok || client.Status != atual {
+		return false, nil
+	}
+	client.Status = novo
+	r.clients[id] = client
+	return true, nil
+}
+
+func (r *MemoryClients) Delete(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.clients, id)
+	return nil
+}
+
+func copiarCliente(client models.Client) models.Client {
+	if client.Enderecos != nil {
+		client.Enderecos = append([]models.EnderecoSalvo(nil), client.Enderecos...)
+	}
+	return client
+}
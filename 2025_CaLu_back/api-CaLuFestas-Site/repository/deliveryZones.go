@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type DeliveryZoneRepository interface {
+	List(ctx context.Context, somenteAtivas bool) ([]models.ZonaEntrega, error)
+	Insert(ctx context.Context, zona models.ZonaEntrega) error
+	Replace(ctx context.Context, zona models.ZonaEntrega) error
+	Delete(ctx context.Context, id bson.ObjectID) error
+}
+
+// Garante que as duas implementações satisfazem DeliveryZoneRepository
+var (
+	_ DeliveryZoneRepository = (*MongoDeliveryZones)(nil)
+	_ DeliveryZoneRepository = (*MemoryDeliveryZones)(nil)
+)
+
+// MongoDeliveryZones guarda as zonas na coleção "zonasEntrega".
+type MongoDeliveryZones struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDeliveryZones(db *mongo.Database) *MongoDeliveryZones {
+	return &MongoDeliveryZones{collection: db.Collection("zonasEntrega")}
+}
+
+func (r *MongoDeliveryZones) List(ctx context.Context, somenteAtivas bool) ([]models.ZonaEntrega, error) {
+	filter := bson.M{}
+	if somenteAtivas {
+		filter["ativa"] = true
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	zonas := []models.ZonaEntrega{}
+	if err := cursor.All(ctx, &zonas); err != nil {
+		return nil, err
+	}
+	return zonas, nil
+}
+
+func (r *MongoDeliveryZones) Insert(ctx context.Context, zona models.ZonaEntrega) error {
+	_, err := r.collection.InsertOne(ctx, zona)
+	return err
+}
+
+func (r *MongoDeliveryZones) Replace(ctx context.Context, zona models.ZonaEntrega) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": zona.ID}, zona)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoDeliveryZones) Delete(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MemoryDeliveryZones guarda as zonas em um mapa, mantendo a ordem de
+// cadastro nas listagens.
+type MemoryDeliveryZones struct {
+	mu    sync.Mutex
+	ordem []bson.ObjectID
+	zonas map[bson.ObjectID]models.ZonaEntrega
+}
+
+func NewMemoryDeliveryZones(zonas ...models.ZonaEntrega) *MemoryDeliveryZones {
+	r := &MemoryDeliveryZones{zonas: map[bson.ObjectID]models.ZonaEntrega{}}
+	for _, zona := range zonas {
+		r.ordem = append(r.ordem, zona.ID)
+		r.zonas[zona.ID] = zona
+	}
+	return r
+}
+
+func (r *MemoryDeliveryZones) List(ctx context.Context, somenteAtivas bool) ([]models.ZonaEntrega, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	zonas := []models.ZonaEntrega{}
+	for _, id := range r.ordem {
+		zona, ok := r.zonas[id]
+		if !ok || (somenteAtivas && !zona.Ativa) {
+			continue
+		}
+		zonas = append(zonas, zona)
+	}
+	return zonas, nil
+}
+
+func (r *MemoryDeliveryZones) Insert(ctx context.Context, zona models.ZonaEntrega) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zonas[zona.ID]; ok {
+		return ErrDuplicate
+	}
+	r.ordem = append(r.ordem, zona.ID)
+	r.zonas[zona.ID] = zona
+	return nil
+}
+
+func (r *MemoryDeliveryZones) Replace(ctx context.Context, zona models.ZonaEntrega) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zonas[zona.ID]; !ok {
+		return ErrNotFound
+	}
+	r.zonas[zona.ID] = zona
+	return nil
+}
+
+func (r *MemoryDeliveryZones) Delete(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zonas[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.zonas, id)
+	return nil
+}
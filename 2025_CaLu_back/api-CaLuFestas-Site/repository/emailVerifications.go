@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type EmailVerificationRepository interface {
+	FindByEmail(ctx context.Context, email string) (models.EmailVerification, error)
+	// Replace grava o código descartando os anteriores do mesmo e-mail.
+	Replace(ctx context.Context, entry models.EmailVerification) error
+	IncrementAttempts(ctx context.Context, id bson.ObjectID) (models.EmailVerification, error)
+	Delete(ctx context.Context, id bson.ObjectID) error
+	DeleteByEmail(ctx context.Context, email string) error
+}
+
+// Garante que as duas implementações satisfazem EmailVerificationRepository
+var (
+	_ EmailVerificationRepository = (*MongoEmailVerifications)(nil)
+	_ EmailVerificationRepository = (*MemoryEmailVerifications)(nil)
+)
+
+// MongoEmailVerifications guarda os códigos na coleção "verificacoesEmail".
+type MongoEmailVerifications struct {
+	collection *mongo.Collection
+}
+
+func NewMongoEmailVerifications(db *mongo.Database) *MongoEmailVerifications {
+	return &MongoEmailVerifications{collection: db.Collection("verificacoesEmail")}
+}
+
+func (r *MongoEmailVerifications) FindByEmail(ctx context.Context, email string) (models.EmailVerification, error) {
+	var entry models.EmailVerification
+	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return entry, ErrNotFound
+	}
+	return entry, err
+}
+
+func (r *MongoEmailVerifications) Replace(ctx context.Context, entry models.EmailVerification) error {
+	if err := r.DeleteByEmail(ctx, entry.Email); err != nil {
+		return err
+	}
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *MongoEmailVerifications) IncrementAttempts(ctx context.Context, id bson.ObjectID) (models.EmailVerification, error) {
+	var entry models.EmailVerification
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return entry, ErrNotFound
+	}
+	return entry, err
+}
+
+func (r *MongoEmailVerifications) Delete(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *MongoEmailVerifications) DeleteByEmail(ctx context.Context, email string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"email": email})
+	return err
+}
+
+// MemoryEmailVerifications guarda os códigos em um mapa. Assim como em
+// MemoryPasswordResets, não há TTL.
+type MemoryEmailVerifications struct {
+	mu      sync.Mutex
+	entries map[bson.ObjectID]models.EmailVerification
+}
+
+func NewMemoryEmailVerifications() *MemoryEmailVerifications {
+	return &MemoryEmailVerifications{entries: map[bson.ObjectID]models.EmailVerification{}}
+}
+
+func (r *MemoryEmailVerifications) FindByEmail(ctx context.Context, email string) (models.EmailVerification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.entries {
+		if entry.Email == email {
+			return entry, nil
+		}
+	}
+	return models.EmailVerification{}, ErrNotFound
+}
+
+func (r *MemoryEmailVerifications) Replace(ctx context.Context, entry models.EmailVerification) error {
+	r.DeleteByEmail(ctx, entry.Email)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[entry.ID] = entry
+	return nil
+}
+
+func (r *MemoryEmailVerifications) IncrementAttempts(ctx context.Context, id bson.ObjectID) (models.EmailVerification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[id]
+	if !ok {
+		return entry, ErrNotFound
+	}
+	entry.Attempts++
+	r.entries[id] = entry
+	return entry, nil
+}
+
+func (r *MemoryEmailVerifications) Delete(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, id)
+	return nil
+}
+
+func (r *MemoryEmailVerifications) DeleteByEmail(ctx context.Context, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, entry := range r.entries {
+		if entry.Email == email {
+			delete(r.entries, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type LocationRepository interface {
+	FindByID(ctx context.Context, id bson.ObjectID) (models.Locacao, error)
+	List(ctx context.Context) ([]models.Locacao, error)
+	// ListByEmail lista as locações do cliente, das mais recentes para as
+	// mais antigas, e devolve também o total sem paginação.
+	ListByEmail(ctx context.Context, email string, page Page) ([]models.Locacao, int64, error)
+	Insert(ctx context.Context, locacao models.Locacao) error
+	Delete(ctx context.Context, id bson.ObjectID) error
+	// UpdateEstadoIf só troca o estado se ele ainda for atual, e diz se trocou.
+	UpdateEstadoIf(ctx context.Context, id bson.ObjectID, atual, novo models.EstadoLocacao) (bool, error)
+	// Reserving devolve as locações que prendem o produto e podem se
+	// sobrepor a [inicio, fim), ignorando a locação ignorar. Locações antigas
+	// sem período estruturado também entram, para o chamador decidir.
+	Reserving(ctx context.Context, produtoID bson.ObjectID, inicio, fim time.Time, ignorar bson.ObjectID) ([]models.Locacao, error)
+	// CountActiveByProduct e CountActiveByEmail contam locações que ainda
+	// prendem estoque.
+	CountActiveByProduct(ctx context.Context, produtoID bson.ObjectID) (int64, error)
+	CountActiveByEmail(ctx context.Context, email string) (int64, error)
+	// AnonymizeByEmail remove os dados pessoais das locações do cliente.
+	AnonymizeByEmail(ctx context.Context, email string) error
+}
+
+// Garante que as duas implementações satisfazem LocationRepository
+var (
+	_ LocationRepository = (*MongoLocations)(nil)
+	_ LocationRepository = (*MemoryLocations)(nil)
+)
+
+const nomeAnonimizado = "Cliente removido"
+
+// MongoLocations guarda as locações na coleção "locations".
+type MongoLocations struct {
+	collection *mongo.Collection
+}
+
+func NewMongoLocations(db *mongo.Database) *MongoLocations {
+	return &MongoLocations{collection: db.Collection("locations")}
+}
+
+func (r *MongoLocations) FindByID(ctx context.Context, id bson.ObjectID) (models.Locacao, error) {
+	var locacao models.Locacao
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&locacao)
+	if err == mongo.ErrNoDocuments {
+		return locacao, ErrNotFound
+	}
+	return locacao, err
+}
+
+func (r *MongoLocations) find(ctx context.Context, filter bson.M, opts ...options.Lister[options.FindOptions]) ([]models.Locacao, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	locations := []models.Locacao{}
+	if err := cursor.All(ctx, &locations); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (r *MongoLocations) List(ctx context.Context) ([]models.Locacao, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *MongoLocations) ListByEmail(ctx context.Context, email string, page Page) ([]models.Locacao, int64, error) {
+	filter := bson.M{"email": email}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.M{"_id": -1}).SetSkip(page.Skip)
+	if page.Limit > 0 {
+		opts.SetLimit(page.Limit)
+	}
+	locations, err := r.find(ctx, filter, opts)
+	return locations, total, err
+}
+
+func (r *MongoLocations) Insert(ctx context.Context, locacao models.Locacao) error {
+	_, err := r.collection.InsertOne(ctx, locacao)
+	return err
+}
+
+func (r *MongoLocations) Delete(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoLocations) UpdateEstadoIf(ctx context.Context, id bson.ObjectID, atual, novo models.EstadoLocacao) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "estado": atual}, bson.M{"$set": bson.M{"estado": novo}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoLocations) Reserving(ctx context.Context, produtoID bson.ObjectID, inicio, fim time.Time, ignorar bson.ObjectID) ([]models.Locacao, error) {
+	return r.find(ctx, bson.M{
+		"items._id": produtoID,
+		"estado":    bson.M{"$nin": models.EstadosSemReserva},
+		"_id":       bson.M{"$ne": ignorar},
+		"$or": []bson.M{
+			{"inicio": bson.M{"$lt": fim}, "fim": bson.M{"$gt": inicio}},
+			// Locações antigas só têm as datas em texto
+			{"inicio": bson.M{"$exists": false}},
+		},
+	})
+}
+
+func (r *MongoLocations) CountActiveByProduct(ctx context.Context, produtoID bson.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"items._id": produtoID,
+		"estado":    bson.M{"$nin": models.EstadosSemReserva},
+	})
+}
+
+func (r *MongoLocations) CountActiveByEmail(ctx context.Context, email string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"email":  email,
+		"estado": bson.M{"$nin": models.EstadosSemReserva},
+	})
+}
+
+func (r *MongoLocations) AnonymizeByEmail(ctx context.Context, email string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"email": email},
+		bson.M{
+			"$set":   bson.M{"email": "", "nome": nomeAnonimizado, "endereco": ""},
+			"$unset": bson.M{"endereco_entrega": ""},
+		},
+	)
+	return err
+}
+
+// MemoryLocations guarda as locações em um mapa.
+type MemoryLocations struct {
+	mu        sync.Mutex
+	locations map[bson.ObjectID]models.Locacao
+}
+
+func NewMemoryLocations(locations ...models.Locacao) *MemoryLocations {
+	r := &MemoryLocations{locations: map[bson.ObjectID]models.Locacao{}}
+	for _, locacao := range locations {
+		r.locations[locacao.ID] = copiarLocacao(locacao)
+	}
+	return r
+}
+
+func (r *MemoryLocations) FindByID(ctx context.Context, id bson.ObjectID) (models.Locacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	locacao, ok := r.locations[id]
+	if !ok {
+		return models.Locacao{}, ErrNotFound
+	}
+	return copiarLocacao(locacao), nil
+}
+
+// filtrar devolve cópias das locações aceitas por fn, da mais antiga para a
+// mais recente.
+func (r *MemoryLocations) filtrar(fn func(models.Locacao) bool) []models.Locacao {
+	locations := []models.Locacao{}
+	for _, locacao := range r.locations {
+		if fn(locacao) {
+			locations = append(locations, copiarLocacao(locacao))
+		}
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return bytes.Compare(locations[i].ID[:], locations[j].ID[:]) < 0
+	})
+	return locations
+}
+
+func (r *MemoryLocations) List(ctx context.Context) ([]models.Locacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.filtrar(func(models.Locacao) bool { return true }), nil
+}
+
+func (r *MemoryLocations) ListByEmail(ctx context.Context, email string, page Page) ([]models.Locacao, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	locations := r.filtrar(func(l models.Locacao) bool { return l.Email == email })
+	for i, j := 0, len(locations)-1; i < j; i, j = i+1, j-1 {
+		locations[i], locations[j] = locations[j], locations[i]
+	}
+	return aplicar(locations, page), int64(len(locations)), nil
+}
+
+func (r *MemoryLocations) Insert(ctx context.Context, locacao models.Locacao) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.locations[locacao.ID]; ok {
+		return ErrDuplicate
+	}
+	r.locations[locacao.ID] = copiarLocacao(locacao)
+	return nil
+}
+
+func (r *MemoryLocations) Delete(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.locations[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.locations, id)
+	return nil
+}
+
+func (r *MemoryLocations) UpdateEstadoIf(ctx context.Context, id bson.ObjectID, atual, novo models.EstadoLocacao) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	locacao, ok := r.locations[id]
+	if !ok || locacao.Estado != atual {
+		return false, nil
+	}
+	locacao.Estado = novo
+	r.locations[id] = locacao
+	return true, nil
+}
+
+func (r *MemoryLocations) Reserving(ctx context.Context, produtoID bson.ObjectID, inicio, fim time.Time, ignorar bson.ObjectID) ([]models.Locacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.filtrar(func(l models.Locacao) bool {
+		if l.ID == ignorar || !reserva(l) || !temProduto(l, produtoID) {
+			return false
+		}
+		return l.Inicio.IsZero() || (l.Inicio.Before(fim) && l.Fim.After(inicio))
+	}), nil
+}
+
+func (r *MemoryLocations) CountActiveByProduct(ctx context.Context, produtoID bson.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.filtrar(func(l models.Locacao) bool { return reserva(l) && temProduto(l, produtoID) }))), nil
+}
+
+func (r *MemoryLocations) CountActiveByEmail(ctx context.Context, email string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.filtrar(func(l models.Locacao) bool { return reserva(l) && l.Email == email }))), nil
+}
+
+func (r *MemoryLocations) AnonymizeByEmail(ctx context.Context, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, locacao := range r.locations {
+		if locacao.Email != email {
+			continue
+		}
+		locacao.Email = ""
+		locacao.Nome = nomeAnonimizado
+		locacao.Endereco = ""
+		locacao.EnderecoEntrega = nil
+		r.locations[id] = locacao
+	}
+	return nil
+}
+
+// reserva replica o filtro "estado fora de EstadosSemReserva" do Mongo, que
+// também aceita locações antigas sem estado.
+func reserva(locacao models.Locacao) bool {
+	for _, estado := range models.EstadosSemReserva {
+		if locacao.Estado == estado {
+			return false
+		}
+	}
+	return true
+}
+
+func temProduto(locacao models.Locacao, produtoID bson.ObjectID) bool {
+	for _, item := range locacao.Items {
+		if item.ProdutoID == produtoID {
+			return true
+		}
+	}
+	return false
+}
+
+func copiarLocacao(locacao models.Locacao) models.Locacao {
+	if locacao.Items != nil {
+		locacao.Items = append([]models.Item(nil), locacao.Items...)
+	}
+	if locacao.EnderecoEntrega != nil {
+		endereco := *locacao.EnderecoEntrega
+		locacao.EnderecoEntrega = &endereco
+	}
+	return locacao
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type PasswordResetRepository interface {
+	// FindLocked devolve o pedido do e-mail que ainda está bloqueado em agora.
+	FindLocked(ctx context.Context, email string, agora time.Time) (models.PasswordResetEntry, error)
+	// FindPending devolve o pedido cujo OTP ainda não foi confirmado.
+	FindPending(ctx context.Context, email string) (models.PasswordResetEntry, error)
+	// FindByResetToken devolve o pedido confirmado que emitiu o reset token.
+	FindByResetToken(ctx context.Context, email, hash string) (models.PasswordResetEntry, error)
+	// Replace descarta os pedidos anteriores do e-mail e grava o novo, para
+	// que só o código mais recente seja aceito.
+	Replace(ctx context.Context, entry models.PasswordResetEntry) error
+	// MarkVerified troca o OTP pelo reset token uma única vez; devolve false
+	// se o pedido já tinha sido confirmado.
+	MarkVerified(ctx context.Context, id bson.ObjectID, resetTokenHash string, expiresAt time.Time) (bool, error)
+	// IncrementAttempts conta um OTP errado e devolve o pedido atualizado.
+	IncrementAttempts(ctx context.Context, id bson.ObjectID) (models.PasswordResetEntry, error)
+	Lock(ctx context.Context, id bson.ObjectID, ate time.Time) error
+	// MarkUsed consome o reset token; devolve false se ele já tinha sido usado.
+	MarkUsed(ctx context.Context, id bson.ObjectID, em time.Time) (bool, error)
+	UnmarkUsed(ctx context.Context, id bson.ObjectID) error
+	DeleteByEmail(ctx context.Context, email string) error
+}
+
+// Garante que as duas implementações satisfazem PasswordResetRepository
+var (
+	_ PasswordResetRepository = (*MongoPasswordResets)(nil)
+	_ PasswordResetRepository = (*MemoryPasswordResets)(nil)
+)
+
+// MongoPasswordResets guarda os pedidos na coleção "senhasEsquecidas".
+type MongoPasswordResets struct {
+	collection *mongo.Collection
+}
+
+func NewMongoPasswordResets(db *mongo.Database) *MongoPasswordResets {
+	return &MongoPasswordResets{collection: db.Collection("senhasEsquecidas")}
+}
+
+func (r *MongoPasswordResets) findOne(ctx context.Context, filter bson.M) (models.PasswordResetEntry, error) {
+	var entry models.PasswordResetEntry
+	err := r.collection.FindOne(ctx, filter).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return entry, ErrNotFound
+	}
+	return entry, err
+}
+
+func (r *MongoPasswordResets) FindLocked(ctx context.Context, email string, agora time.Time) (models.PasswordResetEntry, error) {
+	return r.findOne(ctx, bson.M{"email": email, "locked_until": bson.M{"$gt": agora}})
+}
+
+func (r *MongoPasswordResets) FindPending(ctx context.Context, email string) (models.PasswordResetEntry, error) {
+	return r.findOne(ctx, bson.M{"email": email, "is_verified": false})
+}
+
+func (r *MongoPasswordResets) FindByResetToken(ctx context.Context, email, hash string) (models.PasswordResetEntry, error) {
+	return r.findOne(ctx, bson.M{"email": email, "reset_token_hash": hash, "is_verified": true})
+}
+
+func (r *MongoPasswordResets) Replace(ctx context.Context, entry models.PasswordResetEntry) error {
+	if err := r.DeleteByEmail(ctx, entry.Email); err != nil {
+		return err
+	}
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *MongoPasswordResets) MarkVerified(ctx context.Context, id bson.ObjectID, resetTokenHash string, expiresAt time.Time) (bool, error) {
+	update := bson.M{"$set": bson.M{
+		"is_verified":      true,
+		"reset_token_hash": resetTokenHash,
+		"expires_at":       expiresAt,
+	}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "is_verified": false}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoPasswordResets) IncrementAttempts(ctx context.Context, id bson.ObjectID) (models.PasswordResetEntry, error) {
+	var entry models.PasswordResetEntry
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return entry, ErrNotFound
+	}
+	return entry, err
+}
+
+func (r *MongoPasswordResets) Lock(ctx context.Context, id bson.ObjectID, ate time.Time) error {
+	// expires_at acompanha o bloqueio para o TTL não apagar o pedido antes
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"locked_until": ate, "expires_at": ate}})
+	return err
+}
+
+func (r *MongoPasswordResets) MarkUsed(ctx context.Context, id bson.ObjectID, em time.Time) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": em}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoPasswordResets) UnmarkUsed(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$unset": bson.M{"used_at": ""}})
+	return err
+}
+
+func (r *MongoPasswordResets) DeleteByEmail(ctx context.Context, email string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"email": email})
+	return err
+}
+
+// MemoryPasswordResets guarda os pedidos em um mapa. Não há TTL: pedidos
+// expirados continuam lá e são recusados pela checagem de ExpiresAt.
+type MemoryPasswordResets struct {
+	mu      sync.Mutex
+	entries map[bson.ObjectID]models.PasswordResetEntry
+}
+
+func NewMemoryPasswordResets() *MemoryPasswordResets {
+	return &MemoryPasswordResets{entries: map[bson.ObjectID]models.PasswordResetEntry{}}
+}
+
+func (r *MemoryPasswordResets) find(fn func(models.PasswordResetEntry) bool) (models.PasswordResetEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.entries {
+		if fn(entry) {
+			return entry, nil
+		}
+	}
+	return models.PasswordResetEntry{}, ErrNotFound
+}
+
+func (r *MemoryPasswordResets) FindLocked(ctx context.Context, email string, agora time.Time) (models.PasswordResetEntry, error) {
+	return r.find(func(e models.PasswordResetEntry) bool {
+		return e.Email == email && e.LockedUntil != nil && e.LockedUntil.After(agora)
+	})
+}
+
+func (r *MemoryPasswordResets) FindPending(ctx context.Context, email string) (models.PasswordResetEntry, error) {
+	return r.find(func(e models.PasswordResetEntry) bool {
+		return e.Email == email && !e.IsVerified
+	})
+}
+
+func (r *MemoryPasswordResets) FindByResetToken(ctx context.Context, email, hash string) (models.PasswordResetEntry, error) {
+	return r.find(func(e models.PasswordResetEntry) bool {
+		return e.Email == email && e.IsVerified && e.ResetTokenHash == hash
+	})
+}
+
+func (r *MemoryPasswordResets) Replace(ctx context.Context, entry models.PasswordResetEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existente := range r.entries {
+		if existente.Email == entry.Email {
+			delete(r.entries, id)
+		}
+	}
+	r.entries[entry.ID] = entry
+	return nil
+}
+
+func (r *MemoryPasswordResets) MarkVerified(ctx context.Context, id bson.ObjectID, resetTokenHash string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[id]
+	if !ok || entry.IsVerified {
+		return false, nil
+	}
+	entry.IsVerified = true
+	entry.ResetTokenHash = resetTokenHash
+	entry.ExpiresAt = expiresAt
+	r.entries[id] = entry
+	return true, nil
+}
+
+func (r *MemoryPasswordResets) IncrementAttempts(ctx context.Context, id bson.ObjectID) (models.PasswordResetEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[id]
+	if !ok {
+		return entry, ErrNotFound
+	}
+	entry.Attempts++
+	r.entries[id] = entry
+	return entry, nil
+}
+
+func (r *MemoryPasswordResets) Lock(ctx context.Context, id bson.ObjectID, ate time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[id]; ok {
+		entry.LockedUntil = &ate
+		entry.ExpiresAt = ate
+		r.entries[id] = entry
+	}
+	return nil
+}
+
+func (r *MemoryPasswordResets) MarkUsed(ctx context.Context, id bson.ObjectID, em time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[id]
+	if !ok || entry.UsedAt != nil {
+		return false, nil
+	}
+	entry.UsedAt = &em
+	r.entries[id] = entry
+	return true, nil
+}
+
+func (r *MemoryPasswordResets) UnmarkUsed(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[id]; ok {
+		entry.UsedAt = nil
+		r.entries[id] = entry
+	}
+	return nil
+}
+
+func (r *MemoryPasswordResets) DeleteByEmail(ctx context.Context, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, entry := range r.entries {
+		if entry.Email == email {
+			delete(r.entries, id)
+		}
+	}
+	return nil
+}
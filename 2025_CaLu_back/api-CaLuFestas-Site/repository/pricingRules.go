@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type PricingRuleRepository interface {
+	List(ctx context.Context, somenteAtivas bool) ([]models.RegraPreco, error)
+	Insert(ctx context.Context, regra models.RegraPreco) error
+	Replace(ctx context.Context, regra models.RegraPreco) error
+	Delete(ctx context.Context, id bson.ObjectID) error
+}
+
+// Garante que as duas implementações satisfazem PricingRuleRepository
+var (
+	_ PricingRuleRepository = (*MongoPricingRules)(nil)
+	_ PricingRuleRepository = (*MemoryPricingRules)(nil)
+)
+
+// MongoPricingRules guarda as regras na coleção "regrasPreco".
+type MongoPricingRules struct {
+	collection *mongo.Collection
+}
+
+func NewMongoPricingRules(db *mongo.Database) *MongoPricingRules {
+	return &MongoPricingRules{collection: db.Collection("regrasPreco")}
+}
+
+func (r *MongoPricingRules) List(ctx context.Context, somenteAtivas bool) ([]models.RegraPreco, error) {
+	filter := bson.M{}
+	if somenteAtivas {
+		filter["ativa"] = true
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	regras := []models.RegraPreco{}
+	if err := cursor.All(ctx, &regras); err != nil {
+		return nil, err
+	}
+	return regras, nil
+}
+
+func (r *MongoPricingRules) Insert(ctx context.Context, regra models.RegraPreco) error {
+	_, err := r.collection.InsertOne(ctx, regra)
+	return err
+}
+
+func (r *MongoPricingRules) Replace(ctx context.Context, regra models.RegraPreco) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": regra.ID}, regra)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoPricingRules) Delete(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MemoryPricingRules guarda as regras em um mapa, mantendo a ordem de
+// cadastro nas listagens.
+type MemoryPricingRules struct {
+	mu     sync.Mutex
+	ordem  []bson.ObjectID
+	regras map[bson.ObjectID]models.RegraPreco
+}
+
+func NewMemoryPricingRules(regras ...models.RegraPreco) *MemoryPricingRules {
+	r := &MemoryPricingRules{regras: map[bson.ObjectID]models.RegraPreco{}}
+	for _, regra := range regras {
+		r.ordem = append(r.ordem, regra.ID)
+		r.regras[regra.ID] = regra
+	}
+	return r
+}
+
+func (r *MemoryPricingRules) List(ctx context.Context, somenteAtivas bool) ([]models.RegraPreco, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	regras := []models.RegraPreco{}
+	for _, id := range r.ordem {
+		regra, ok := r.regras[id]
+		if !ok || (somenteAtivas && !regra.Ativa) {
+			continue
+		}
+		regras = append(regras, regra)
+	}
+	return regras, nil
+}
+
+func (r *MemoryPricingRules) Insert(ctx context.Context, regra models.RegraPreco) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.regras[regra.ID]; ok {
+		return ErrDuplicate
+	}
+	r.ordem = append(r.ordem, regra.ID)
+	r.regras[regra.ID] = regra
+	return nil
+}
+
+func (r *MemoryPricingRules) Replace(ctx context.Context, regra models.RegraPreco) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.regras[regra.ID]; !ok {
+		return ErrNotFound
+	}
+	r.regras[regra.ID] = regra
+	return nil
+}
+
+func (r *MemoryPricingRules) Delete(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.regras[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.regras, id)
+	return nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type ProductRepository interface {
+	FindByID(ctx context.Context, id bson.ObjectID) (models.Product, error)
+	List(ctx context.Context, incluirArquivados bool) ([]models.Product, error)
+	Insert(ctx context.Context, product models.Product) error
+	// UpdateFields grava só os campos editáveis, sem tocar no contador de
+	// itens em locação, que as locações alteram em paralelo. As condições são
+	// checadas na própria escrita: falha com ErrArchived se o produto estiver
+	// arquivado e com ErrBelowReserved se a quantidade nova não cobrir as
+	// unidades em locação.
+	UpdateFields(ctx context.Context, product models.Product) error
+	// SetArchived arquiva o produto; arquivadoEm nil o devolve ao catálogo.
+	SetArchived(ctx context.Context, id bson.ObjectID, arquivadoEm *time.Time) error
+	// AdjustReserved soma delta ao contador de itens em locação. Reservas
+	// novas (delta positivo) falham com ErrArchived em produtos arquivados.
+	AdjustReserved(ctx context.Context, id bson.ObjectID, delta int) error
+}
+
+// Garante que as duas implementações satisfazem ProductRepository
+var (
+	_ ProductRepository = (*MongoProducts)(nil)
+	_ ProductRepository = (*MemoryProducts)(nil)
+)
+
+// MongoProducts guarda os produtos na coleção "produtos".
+type MongoProducts struct {
+	collection *mongo.Collection
+}
+
+func NewMongoProducts(db *mongo.Database) *MongoProducts {
+	return &MongoProducts{collection: db.Collection("produtos")}
+}
+
+func (r *MongoProducts) FindByID(ctx context.Context, id bson.ObjectID) (models.Product, error) {
+	var product models.Product
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return product, ErrNotFound
+	}
+	return product, err
+}
+
+func (r *MongoProducts) List(ctx context.Context, incluirArquivados bool) ([]models.Product, error) {
+	filter := bson.M{}
+	if !incluirArquivados {
+		filter["arquivado"] = bson.M{"$ne": true}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products := []models.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *MongoProducts) Insert(ctx context.Context, product models.Product) error {
+	_, err := r.collection.InsertOne(ctx, product)
+	return err
+}
+
+func (r *MongoProducts) UpdateFields(ctx context.Context, product models.Product) error {
+	filter := bson.M{
+		"_id":                   product.ID,
+		"arquivado":             bson.M{"$ne": true},
+		"quantidade_em_locacao": bson.M{"$lte": product.Quantidade},
+	}
+	update := bson.M{"$set": bson.M{
+		"nome":         product.Nome,
+		"categoria":    product.Categoria,
+		"subcategoria": product.Subcategoria,
+		"quantidade":   product.Quantidade,
+		"preco":        product.Preco,
+		"descricao":    product.Descricao,
+		"imagem":       product.Imagem,
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 1 {
+		return nil
+	}
+
+	// Nada casou com o filtro: descobre qual condição falhou
+	atual, err := r.FindByID(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+	return conferirEdicao(atual, product)
+}
+
+func (r *MongoProducts) SetArchived(ctx context.Context, id bson.ObjectID, arquivadoEm *time.Time) error {
+	update := bson.M{"$set": bson.M{"arquivado": true, "arquivado_em": arquivadoEm}}
+	if arquivadoEm == nil {
+		update = bson.M{"$set": bson.M{"arquivado": false}, "$unset": bson.M{"arquivado_em": ""}}
+	}
+	return r.updateOne(ctx, id, update)
+}
+
+func (r *MongoProducts) AdjustReserved(ctx context.Context, id bson.ObjectID, delta int) error {
+	update := bson.M{"$inc": bson.M{"quantidade_em_locacao": delta}}
+	if delta <= 0 {
+		return r.updateOne(ctx, id, update)
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "arquivado": bson.M{"$ne": true}}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrArchived
+	}
+	return nil
+}
+
+func (r *MongoProducts) updateOne(ctx context.Context, id bson.ObjectID, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MemoryProducts guarda os produtos em um mapa, mantendo a ordem de
+// cadastro nas listagens.
+type MemoryProducts struct {
+	mu       sync.Mutex
+	ordem    []bson.ObjectID
+	products map[bson.ObjectID]models.Product
+}
+
+func NewMemoryProducts(products ...models.Product) *MemoryProducts {
+	r := &MemoryProducts{products: map[bson.ObjectID]models.Product{}}
+	for _, product := range products {
+		r.ordem = append(r.ordem, product.ID)
+		r.products[product.ID] = copiarProduto(product)
+	}
+	return r
+}
+
+func (r *MemoryProducts) FindByID(ctx context.Context, id bson.ObjectID) (models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return models.Product{}, ErrNotFound
+	}
+	return copiarProduto(product), nil
+}
+
+func (r *MemoryProducts) List(ctx context.Context, incluirArquivados bool) ([]models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	products := []models.Product{}
+	for _, id := range r.ordem {
+		product := r.products[id]
+		if product.Arquivado && !incluirArquivados {
+			continue
+		}
+		products = append(products, copiarProduto(product))
+	}
+	return products, nil
+}
+
+func (r *MemoryProducts) Insert(ctx context.Context, product models.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[product.ID]; ok {
+		return ErrDuplicate
+	}
+	r.ordem = append(r.ordem, product.ID)
+	r.products[product.ID] = copiarProduto(product)
+	return nil
+}
+
+func (r *MemoryProducts) UpdateFields(ctx context.Context, product models.Product) error {
+	return r.alterar(product.ID, func(p *models.Product) error {
+		if err := conferirEdicao(*p, product); err != nil {
+			return err
+		}
+		p.Nome = product.Nome
+		p.Categoria = product.Categoria
+		p.Subcategoria = product.Subcategoria
+		p.Quantidade = product.Quantidade
+		p.Preco = product.Preco
+		p.Descricao = product.Descricao
+		p.Imagem = append([]string(nil), product.Imagem...)
+		return nil
+	})
+}
+
+func (r *MemoryProducts) SetArchived(ctx context.Context, id bson.ObjectID, arquivadoEm *time.Time) error {
+	return r.alterar(id, func(p *models.Product) error {
+		p.Arquivado = arquivadoEm != nil
+		p.ArquivadoEm = arquivadoEm
+		return nil
+	})
+}
+
+func (r *MemoryProducts) AdjustReserved(ctx context.Context, id bson.ObjectID, delta int) error {
+	return r.alterar(id, func(p *models.Product) error {
+		if delta > 0 && p.Arquivado {
+			return ErrArchived
+		}
+		p.QuantidadeEmLocacao += delta
+		return nil
+	})
+}
+
+func (r *MemoryProducts) alterar(id bson.ObjectID, fn func(*models.Product) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := fn(&product); err != nil {
+		return err
+	}
+	r.products[id] = product
+	return nil
+}
+
+// conferirEdicao aplica as condições de UpdateFields ao produto gravado.
+func conferirEdicao(atual, novo models.Product) error {
+	if atual.Arquivado {
+		return ErrArchived
+	}
+	if novo.Quantidade < atual.QuantidadeEmLocacao {
+		return ErrBelowReserved
+	}
+	return nil
+}
+
+func copiarProduto(product models.Product) models.Product {
+	if product.Imagem != nil {
+		product.Imagem = append([]string(nil), product.Imagem...)
+	}
+	return product
+}
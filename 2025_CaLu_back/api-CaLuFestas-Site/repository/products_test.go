@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/internal/mongotest"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// testarProdutos roda o mesmo cenário nas duas implementações; a do Mongo
+// só quando MONGO_TEST_URI estiver definida.
+func testarProdutos(t *testing.T, cenario func(t *testing.T, r ProductRepository)) {
+	t.Run("memoria", func(t *testing.T) {
+		cenario(t, NewMemoryProducts())
+	})
+	t.Run("mongo", func(t *testing.T) {
+		cenario(t, NewMongoProducts(mongotest.Banco(t)))
+	})
+}
+
+func TestUpdateFieldsConfereEstadoAtual(t *testing.T) {
+	testarProdutos(t, func(t *testing.T, r ProductRepository) {
+		ctx := context.Background()
+		mesa := models.Product{ID: bson.NewObjectID(), Nome: "Mesa", Quantidade: 5, Preco: 20}
+		if err := r.Insert(ctx, mesa); err != nil {
+			t.Fatal(err)
+		}
+
+		// Edição feita sobre uma leitura anterior às reservas
+		lida, err := r.FindByID(ctx, mesa.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.AdjustReserved(ctx, mesa.ID, 4); err != nil {
+			t.Fatal(err)
+		}
+		lida.Quantidade = 3
+		if err := r.UpdateFields(ctx, lida); !errors.Is(err, ErrBelowReserved) {
+			t.Fatalf("erro = %v, esperado ErrBelowReserved", err)
+		}
+
+		// Edição feita sobre uma leitura anterior ao arquivamento
+		lida.Quantidade = 4
+		agora := time.Now().UTC()
+		if err := r.SetArchived(ctx, mesa.ID, &agora); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.UpdateFields(ctx, lida); !errors.Is(err, ErrArchived) {
+			t.Fatalf("erro = %v, esperado ErrArchived", err)
+		}
+		if err := r.AdjustReserved(ctx, mesa.ID, 1); !errors.Is(err, ErrArchived) {
+			t.Fatalf("reserva em produto arquivado: erro = %v, esperado ErrArchived", err)
+		}
+		// Devolver unidades continua possível
+		if err := r.AdjustReserved(ctx, mesa.ID, -4); err != nil {
+			t.Fatal(err)
+		}
+
+		lida.ID = bson.NewObjectID()
+		if err := r.UpdateFields(ctx, lida); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("erro = %v, esperado ErrNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type QuoteRepository interface {
+	Insert(ctx context.Context, cotacao models.Cotacao) error
+	FindByID(ctx context.Context, id bson.ObjectID) (models.Cotacao, error)
+	// MarkUsed marca a cotação como usada uma única vez; devolve false se
+	// ela já tinha sido usada.
+	MarkUsed(ctx context.Context, id bson.ObjectID) (bool, error)
+	UnmarkUsed(ctx context.Context, id bson.ObjectID) error
+}
+
+// Garante que as duas implementações satisfazem QuoteRepository
+var (
+	_ QuoteRepository = (*MongoQuotes)(nil)
+	_ QuoteRepository = (*MemoryQuotes)(nil)
+)
+
+// MongoQuotes guarda as cotações na coleção "cotacoes", que expira os
+// documentos pelo índice TTL em expira_em.
+type MongoQuotes struct {
+	collection *mongo.Collection
+}
+
+func NewMongoQuotes(db *mongo.Database) *MongoQuotes {
+	return &MongoQuotes{collection: db.Collection("cotacoes")}
+}
+
+func (r *MongoQuotes) Insert(ctx context.Context, cotacao models.Cotacao) error {
+	_, err := r.collection.InsertOne(ctx, cotacao)
+	return err
+}
+
+func (r *MongoQuotes) FindByID(ctx context.Context, id bson.ObjectID) (models.Cotacao, error) {
+	var cotacao models.Cotacao
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&cotacao)
+	if err == mongo.ErrNoDocuments {
+		return cotacao, ErrNotFound
+	}
+	return cotacao, err
+}
+
+func (r *MongoQuotes) MarkUsed(ctx context.Context, id bson.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "usada": false}, bson.M{"$set": bson.M{"usada": true}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoQuotes) UnmarkUsed(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"usada": false}})
+	return err
+}
+
+// MemoryQuotes guarda as cotações em um mapa. Não há TTL; a expiração do
+// quote_id continua valendo pelo próprio token.
+type MemoryQuotes struct {
+	mu       sync.Mutex
+	cotacoes map[bson.ObjectID]models.Cotacao
+}
+
+func NewMemoryQuotes() *MemoryQuotes {
+	return &MemoryQuotes{cotacoes: map[bson.ObjectID]models.Cotacao{}}
+}
+
+func (r *MemoryQuotes) Insert(ctx context.Context, cotacao models.Cotacao) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cotacoes[cotacao.ID]; ok {
+		return ErrDuplicate
+	}
+	r.cotacoes[cotacao.ID] = cotacao
+	return nil
+}
+
+func (r *MemoryQuotes) FindByID(ctx context.Context, id bson.ObjectID) (models.Cotacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cotacao, ok := r.cotacoes[id]
+	if !ok {
+		return models.Cotacao{}, ErrNotFound
+	}
+	return cotacao, nil
+}
+
+func (r *MemoryQuotes) MarkUsed(ctx context.Context, id bson.ObjectID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cotacao, ok := r.cotacoes[id]
+	if !ok || cotacao.Usada {
+		return false, nil
+	}
+	cotacao.Usada = true
+	r.cotacoes[id] = cotacao
+	return true, nil
+}
+
+func (r *MemoryQuotes) UnmarkUsed(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cotacao, ok := r.cotacoes[id]; ok {
+		cotacao.Usada = false
+		r.cotacoes[id] = cotacao
+	}
+	return nil
+}
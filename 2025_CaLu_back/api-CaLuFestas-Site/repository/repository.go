@@ -0,0 +1,41 @@
+// Package repository isola o acesso aos dados das coleções principais. Cada
+// repositório tem uma implementação sobre o MongoDB e outra em memória, que
+// permite testar os handlers sem um banco.
+//
+// Os métodos recebem o contexto da unidade de trabalho (tx.Context()), então
+// as implementações Mongo participam da transação quando houver uma.
+package repository
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotFound  = errors.New("registro não encontrado")
+	ErrDuplicate = errors.New("registro duplicado")
+	// ErrArchived indica um produto fora do catálogo, que não aceita edição
+	// nem novas reservas.
+	ErrArchived = errors.New("produto arquivado")
+	// ErrBelowReserved indica uma quantidade menor que as unidades já em
+	// locação.
+	ErrBelowReserved = errors.New("quantidade abaixo das unidades em locação")
+)
+
+// Page delimita uma listagem. Limit zero devolve todos os registros a partir
+// de Skip.
+type Page struct {
+	Skip  int64
+	Limit int64
+}
+
+// aplicar recorta uma lista já ordenada, como o Mongo faria com skip/limit.
+func aplicar[T any](itens []T, page Page) []T {
+	if page.Skip >= int64(len(itens)) {
+		return []T{}
+	}
+	itens = itens[page.Skip:]
+	if page.Limit > 0 && page.Limit < int64(len(itens)) {
+		itens = itens[:page.Limit]
+	}
+	return itens
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// TokenRepository cuida dos refresh tokens e da lista de access tokens
+// revogados, que o AuthMiddleware consulta a cada requisição.
+type TokenRepository interface {
+	InsertRefresh(ctx context.Context, token models.RefreshToken) error
+	FindRefreshByHash(ctx context.Context, hash string) (models.RefreshToken, error)
+	// MarkRefreshUsed marca o token como usado uma única vez; devolve false
+	// se ele já tinha sido usado.
+	MarkRefreshUsed(ctx context.Context, id bson.ObjectID, em time.Time) (bool, error)
+	UnmarkRefreshUsed(ctx context.Context, id bson.ObjectID) error
+	RevokeFamily(ctx context.Context, familia string, em time.Time) error
+	RevokeByEmail(ctx context.Context, email string, em time.Time) error
+	// RevokeAccess não falha se o jti já estiver na lista.
+	RevokeAccess(ctx context.Context, token models.TokenRevogado) error
+	IsAccessRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// Garante que as duas implementações satisfazem TokenRepository
+var (
+	_ TokenRepository = (*MongoTokens)(nil)
+	_ TokenRepository = (*MemoryTokens)(nil)
+)
+
+// MongoTokens usa as coleções "refreshTokens" e "tokensRevogados".
+type MongoTokens struct {
+	refresh   *mongo.Collection
+	revogados *mongo.Collection
+}
+
+func NewMongoTokens(db *mongo.Database) *MongoTokens {
+	return &MongoTokens{
+		refresh:   db.Collection("refreshTokens"),
+		revogados: db.Collection("tokensRevogados"),
+	}
+}
+
+func (r *MongoTokens) InsertRefresh(ctx context.Context, token models.RefreshToken) error {
+	_, err := r.refresh.InsertOne(ctx, token)
+	return err
+}
+
+func (r *MongoTokens) FindRefreshByHash(ctx context.Context, hash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.refresh.FindOne(ctx, bson.M{"hash": hash}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return token, ErrNotFound
+	}
+	return token, err
+}
+
+func (r *MongoTokens) MarkRefreshUsed(ctx context.Context, id bson.ObjectID, em time.Time) (bool, error) {
+	result, err := r.refresh.UpdateOne(ctx,
+		bson.M{"_id": id, "usado_em": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"usado_em": em}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoTokens) UnmarkRefreshUsed(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.refresh.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$unset": bson.M{"usado_em": ""}})
+	return err
+}
+
+func (r *MongoTokens) RevokeFamily(ctx context.Context, familia string, em time.Time) error {
+	return r.revogar(ctx, bson.M{"familia": familia}, em)
+}
+
+func (r *MongoTokens) RevokeByEmail(ctx context.Context, email string, em time.Time) error {
+	return r.revogar(ctx, bson.M{"email": email}, em)
+}
+
+func (r *MongoTokens) revogar(ctx context.Context, filter bson.M, em time.Time) error {
+	filter["revogado_em"] = bson.M{"$exists": false}
+	_, err := r.refresh.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revogado_em": em}})
+	return err
+}
+
+func (r *MongoTokens) RevokeAccess(ctx context.Context, token models.TokenRevogado) error {
+	_, err := r.revogados.InsertOne(ctx, token)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *MongoTokens) IsAccessRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := r.revogados.CountDocuments(ctx, bson.M{"jti": jti})
+	return n > 0, err
+}
+
+// MemoryTokens guarda os tokens em mapas.
+type MemoryTokens struct {
+	mu        sync.Mutex
+	refresh   map[bson.ObjectID]models.RefreshToken
+	revogados map[string]models.TokenRevogado
+}
+
+func NewMemoryTokens() *MemoryTokens {
+	return &MemoryTokens{
+		refresh:   map[bson.ObjectID]models.RefreshToken{},
+		revogados: map[string]models.TokenRevogado{},
+	}
+}
+
+func (r *MemoryTokens) InsertRefresh(ctx context.Context, token models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existente := range r.refresh {
+		if existente.Hash == token.Hash {
+			return ErrDuplicate
+		}
+	}
+	r.refresh[token.ID] = token
+	return nil
+}
+
+func (r *MemoryTokens) FindRefreshByHash(ctx context.Context, hash string) (models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.refresh {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return models.RefreshToken{}, ErrNotFound
+}
+
+func (r *MemoryTokens) MarkRefreshUsed(ctx context.Context, id bson.ObjectID, em time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refresh[id]
+	if !ok || token.UsadoEm != nil {
+		return false, nil
+	}
+	token.UsadoEm = &em
+	r.refresh[id] = token
+	return true, nil
+}
+
+func (r *MemoryTokens) UnmarkRefreshUsed(ctx context.Context, id bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.refresh[id]; ok {
+		token.UsadoEm = nil
+		r.refresh[id] = token
+	}
+	return nil
+}
+
+func (r *MemoryTokens) RevokeFamily(ctx context.Context, familia string, em time.Time) error {
+	r.revogar(func(t models.RefreshToken) bool { return t.Familia == familia }, em)
+	return nil
+}
+
+func (r *MemoryTokens) RevokeByEmail(ctx context.Context, email string, em time.Time) error {
+	r.revogar(func(t models.RefreshToken) bool { return t.Email == email }, em)
+	return nil
+}
+
+func (r *MemoryTokens) revogar(fn func(models.RefreshToken) bool, em time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.refresh {
+		if token.RevogadoEm == nil && fn(token) {
+			token.RevogadoEm = &em
+			r.refresh[id] = token
+		}
+	}
+}
+
+func (r *MemoryTokens) RevokeAccess(ctx context.Context, token models.TokenRevogado) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revogados[token.JTI] = token
+	return nil
+}
+
+func (r *MemoryTokens) IsAccessRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.revogados[jti]
+	return ok, nil
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func CEPRoutes(r *gin.RouterGroup, h *controllers.Handler, limits ratelimit.Store) {
+	// Cada consulta vira uma chamada ao serviço externo
+	cepPorIP := ratelimit.NewLimiter(limits, "cep-ip", 60, time.Minute)
+
+	r.GET("/cep/:cep", middle.RateLimit(cepPorIP), h.LookupCEP)
+}
@@ -1,18 +1,30 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
-func ClientRoutes(r *gin.RouterGroup) {
+func ClientRoutes(r *gin.RouterGroup, h *controllers.Handler, limits ratelimit.Store) {
+	loginPorIP := ratelimit.NewLimiter(limits, "login-ip", 20, 15*time.Minute)
+	forgotPorIP := ratelimit.NewLimiter(limits, "forgot-ip", 10, time.Hour)
+	verifyPorIP := ratelimit.NewLimiter(limits, "verify-ip", 20, 15*time.Minute)
+
 	clients := r.Group("/clients")
 	{
-		clients.POST("/", controllers.Register)
-		clients.POST("/login", controllers.Login)
-		clients.POST("/ForgotPassword", controllers.ForgotPassword)
-		clients.POST("/verifyCode", controllers.VerifyCode)
-		clients.POST("/ResetPassword", controllers.UpdatePassword)
+		clients.POST("/", h.Register)
+		clients.POST("/login", middle.RateLimit(loginPorIP), h.Login)
+		clients.POST("/ForgotPassword", middle.RateLimit(forgotPorIP), h.ForgotPassword)
+		clients.POST("/verifyCode", middle.RateLimit(verifyPorIP), h.VerifyCode)
+		clients.POST("/ResetPassword", h.UpdatePassword)
+		clients.POST("/verifyEmail", middle.RateLimit(verifyPorIP), h.VerifyEmail)
+		clients.POST("/resendVerification", middle.RateLimit(forgotPorIP), h.ResendVerification)
+		clients.POST("/refresh", h.RefreshToken)
+		clients.POST("/logout", h.Logout)
 		
 	}
 }
\ No newline at end of file
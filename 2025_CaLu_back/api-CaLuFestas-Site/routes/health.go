@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// HealthRoutes fica fora de /api e sem autenticação, para as sondas do
+// orquestrador.
+func HealthRoutes(r *gin.RouterGroup, h *controllers.Handler) {
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+}
@@ -2,16 +2,19 @@ package routes
 
 import (
 	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
 	"github.com/gin-gonic/gin"
 )
 
-func LocationRoutes(r *gin.RouterGroup) {
+func LocationRoutes(r *gin.RouterGroup, h *controllers.Handler) {
 	location := r.Group("/locations")
 	{
-		location.POST("/", controllers.CreateLocation)
-		location.GET("/", controllers.GetLocations)
-		location.PUT("/:id", controllers.UpdateLocation)
-		location.POST("/:id/delete", controllers.DeleteLocation)
-		location.POST("/cliente", controllers.LocationsByClient)
+		location.POST("/", h.CreateLocation)
+		location.GET("/", middle.RequireRole(models.CargoAdmin), h.GetLocations)
+		// Clientes só podem cancelar as próprias locações; o handler faz essa checagem
+		location.PUT("/:id", h.UpdateLocation)
+		location.POST("/:id/delete", middle.RequireRole(models.CargoAdmin), h.DeleteLocation)
+		location.POST("/cliente", h.LocationsByClient)
 	}
 }
\ No newline at end of file
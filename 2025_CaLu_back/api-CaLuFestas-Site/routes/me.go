@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// MeRoutes reúne as rotas do próprio usuário autenticado.
+func MeRoutes(r *gin.RouterGroup, h *controllers.Handler) {
+	me := r.Group("/me")
+	{
+		me.GET("", h.GetProfile)
+		me.PATCH("", h.UpdateProfile)
+		me.DELETE("", h.DeleteAccount)
+		me.POST("/password", h.ChangePassword)
+		me.GET("/locations", h.MyLocations)
+	}
+}
@@ -2,13 +2,21 @@ package routes
 
 import (
 	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
 	"github.com/gin-gonic/gin"
 )
 
-func PrivateClientRoutes(r *gin.RouterGroup) {
+func PrivateClientRoutes(r *gin.RouterGroup, h *controllers.Handler) {
 	privateClients := r.Group("/privateClients")
 	{
-		privateClients.GET("/", controllers.GetClients)
-		privateClients.GET("/me", controllers.Me)
+		privateClients.GET("/", middle.RequireRole(models.CargoAdmin), h.GetClients)
+		privateClients.GET("/me", h.Me)
+
+		admin := privateClients.Group("/", middle.RequireRole(models.CargoAdmin))
+		admin.GET("/:id", h.GetClient)
+		admin.PATCH("/:id/cargo", h.UpdateClientCargo)
+		admin.POST("/:id/deactivate", h.DeactivateClient)
+		admin.POST("/:id/activate", h.ActivateClient)
 	}
 }
\ No newline at end of file
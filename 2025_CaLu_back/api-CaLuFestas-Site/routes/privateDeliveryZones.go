@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+func PrivateDeliveryZoneRoutes(r *gin.RouterGroup, h *controllers.Handler) {
+	deliveryZones := r.Group("/privateDeliveryZones")
+	deliveryZones.Use(middle.RequireRole(models.CargoAdmin))
+	{
+		deliveryZones.GET("/", h.GetDeliveryZones)
+		deliveryZones.POST("/", h.CreateDeliveryZone)
+		deliveryZones.PUT("/:id", h.UpdateDeliveryZone)
+		deliveryZones.DELETE("/:id", h.DeleteDeliveryZone)
+	}
+}
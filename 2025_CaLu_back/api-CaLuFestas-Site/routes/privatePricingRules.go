@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+func PrivatePricingRuleRoutes(r *gin.RouterGroup, h *controllers.Handler) {
+	pricingRules := r.Group("/privatePricingRules")
+	pricingRules.Use(middle.RequireRole(models.CargoAdmin))
+	{
+		pricingRules.GET("/", h.GetPricingRules)
+		pricingRules.POST("/", h.CreatePricingRule)
+		pricingRules.PUT("/:id", h.UpdatePricingRule)
+		pricingRules.DELETE("/:id", h.DeletePricingRule)
+	}
+}
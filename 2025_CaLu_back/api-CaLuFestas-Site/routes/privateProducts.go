@@ -2,12 +2,20 @@ package routes
 
 import (
 	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
 	"github.com/gin-gonic/gin"
 )
 
-func PrivateProductRoutes(r *gin.RouterGroup) {
+func PrivateProductRoutes(r *gin.RouterGroup, h *controllers.Handler) {
 	privateProducts := r.Group("/privateProducts")
+	privateProducts.Use(middle.RequireRole(models.CargoAdmin))
 	{
-		privateProducts.POST("/register", controllers.CreateProduct)
+		privateProducts.GET("/", h.GetAllProducts)
+		privateProducts.POST("/register", h.CreateProduct)
+		privateProducts.PUT("/:id", h.UpdateProduct)
+		privateProducts.PATCH("/:id", h.PatchProduct)
+		privateProducts.DELETE("/:id", h.ArchiveProduct)
+		privateProducts.POST("/:id/restore", h.RestoreProduct)
 	}
 }
\ No newline at end of file
@@ -5,9 +5,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func ProductRoutes(r *gin.RouterGroup) {
+func ProductRoutes(r *gin.RouterGroup, h *controllers.Handler) {
 	products := r.Group("/products")
 	{
-		products.GET("/", controllers.GetProducts)
+		products.GET("/", h.GetProducts)
+		products.GET("/:id", h.GetProduct)
+		products.GET("/:id/availability", h.ProductAvailability)
 	}
 }
\ No newline at end of file
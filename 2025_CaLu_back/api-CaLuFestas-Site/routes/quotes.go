@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func QuoteRoutes(r *gin.RouterGroup, h *controllers.Handler) {
+	quotes := r.Group("/quotes")
+	{
+		quotes.POST("/", h.CreateQuote)
+	}
+}
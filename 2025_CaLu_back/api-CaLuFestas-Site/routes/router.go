@@ -1,34 +1,52 @@
 package routes
 
 import (
+	"log"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/Psnsilvino/CaluFestas-Site-api/config"
+	"github.com/Psnsilvino/CaluFestas-Site-api/controllers"
 	middle "github.com/Psnsilvino/CaluFestas-Site-api/middleware"
+	"github.com/Psnsilvino/CaluFestas-Site-api/utils/ratelimit"
 )
 
-func SetupRouter() *gin.Engine {
+func SetupRouter(cfg config.Config, h *controllers.Handler, limits ratelimit.Store) *gin.Engine {
 	router := gin.Default()
 
+	// Sem proxies confiáveis o X-Forwarded-For é ignorado; do contrário
+	// qualquer cliente escolheria o IP usado nos limites de tentativas
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES inválido: %v", err)
+	}
+
 	router.Use(cors.New(cors.Config{
-        AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000"}, // CaluFestas-Site (5173) and calu-chat (3000)
+        AllowOrigins:     cfg.CORSOrigins,
         AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
         AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
         ExposeHeaders:    []string{"Content-Length"},
         AllowCredentials: true,
     }))
 
+	HealthRoutes(&router.RouterGroup, h)
+
 	api := router.Group("/api") // Agrupa todas as rotas dentro de /api
-	ClientRoutes(api)   // Adiciona rotas de usuários
-    ProductRoutes(api)
+	ClientRoutes(api, h, limits)   // Adiciona rotas de usuários
+    ProductRoutes(api, h)
+    QuoteRoutes(api, h)
+    CEPRoutes(api, h, limits)
 
 	// Agora criamos um grupo protegido pelo AuthMiddleware
     protected := api.Group("/")
-    protected.Use(middle.AuthMiddleware()) // tudo que estiver aqui exigirá o JWT
+    protected.Use(middle.AuthMiddleware(h.Clients(), h.Tokens(), []byte(cfg.JWTSecret))) // tudo que estiver aqui exigirá o JWT
 
     // Rotas protegidas
-    PrivateClientRoutes(protected)
-    PrivateProductRoutes(protected)
-    LocationRoutes(protected)
+    PrivateClientRoutes(protected, h)
+    PrivateProductRoutes(protected, h)
+    LocationRoutes(protected, h)
+    PrivatePricingRuleRoutes(protected, h)
+    PrivateDeliveryZoneRoutes(protected, h)
+    MeRoutes(protected, h)
 	
 
 	return router
@@ -0,0 +1,165 @@
+package cep
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+)
+
+var ErrNotFound = errors.New("CEP não encontrado")
+
+// AddressResolver completa um endereço a partir do CEP. Número e complemento
+// nunca vêm do resolver.
+type AddressResolver interface {
+	Resolve(ctx context.Context, cep string) (models.Endereco, error)
+}
+
+// Ensure ViaCEP and Static implement AddressResolver
+var (
+	_ AddressResolver = (*ViaCEP)(nil)
+	_ AddressResolver = Static(nil)
+)
+
+// ViaCEP consulta a API pública do ViaCEP (ou outra com o mesmo formato).
+type ViaCEP struct {
+	baseURL string
+	client  *http.Client
+}
+
+const DefaultViaCEPURL = "https://viacep.com.br/ws"
+
+func NewViaCEP(baseURL string) *ViaCEP {
+	if baseURL == "" {
+		baseURL = DefaultViaCEPURL
+	}
+	return &ViaCEP{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type respostaViaCEP struct {
+	CEP        string `json:"cep"`
+	Logradouro string `json:"logradouro"`
+	Bairro     string `json:"bairro"`
+	Localidade string `json:"localidade"`
+	UF         string `json:"uf"`
+	// O ViaCEP responde 200 com {"erro": true} para CEPs inexistentes; versões
+	// antigas mandavam o valor como string
+	Erro any `json:"erro"`
+}
+
+func (v *ViaCEP) Resolve(ctx context.Context, cep string) (models.Endereco, error) {
+	cep, err := models.NormalizarCEP(cep)
+	if err != nil {
+		return models.Endereco{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s/json/", v.baseURL, cep), nil)
+	if err != nil {
+		return models.Endereco{}, err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return models.Endereco{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return models.Endereco{}, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return models.Endereco{}, fmt.Errorf("viacep: status %d", resp.StatusCode)
+	}
+
+	var body respostaViaCEP
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return models.Endereco{}, fmt.Errorf("viacep: %w", err)
+	}
+	if body.Erro != nil && body.Erro != false && body.Erro != "false" {
+		return models.Endereco{}, ErrNotFound
+	}
+
+	return models.Endereco{
+		CEP:        cep,
+		Logradouro: body.Logradouro,
+		Bairro:     body.Bairro,
+		Cidade:     body.Localidade,
+		UF:         body.UF,
+	}, nil
+}
+
+// Static resolve CEPs a partir de um mapa fixo, sem acesso à rede. Serve
+// para desenvolvimento offline e testes.
+type Static map[string]models.Endereco
+
+//go:embed fixtures.json
+var fixtures []byte
+
+// Fixtures devolve um Static com os CEPs de fixtures.json, usado quando o
+// resolver é "offline" e nenhum arquivo é indicado.
+func Fixtures() Static {
+	s, err := LoadStatic(bytes.NewReader(fixtures))
+	if err != nil {
+		panic("cep: fixtures.json inválido: " + err.Error())
+	}
+	return s
+}
+
+// LoadStaticFile lê um Static de um arquivo no formato de fixtures.json.
+func LoadStaticFile(arquivo string) (Static, error) {
+	f, err := os.Open(arquivo)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := LoadStatic(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", arquivo, err)
+	}
+	return s, nil
+}
+
+// LoadStatic lê um objeto JSON de CEP para endereço. Os CEPs podem vir com ou
+// sem hífen.
+func LoadStatic(r io.Reader) (Static, error) {
+	var enderecos map[string]models.Endereco
+	if err := json.NewDecoder(r).Decode(&enderecos); err != nil {
+		return nil, err
+	}
+
+	s := Static{}
+	for chave, endereco := range enderecos {
+		cep, err := models.NormalizarCEP(chave)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", chave, err)
+		}
+		s[cep] = endereco
+	}
+	return s, nil
+}
+
+func (s Static) Resolve(ctx context.Context, cep string) (models.Endereco, error) {
+	cep, err := models.NormalizarCEP(cep)
+	if err != nil {
+		return models.Endereco{}, err
+	}
+	endereco, ok := s[cep]
+	if !ok {
+		return models.Endereco{}, ErrNotFound
+	}
+	endereco.CEP = cep
+	return endereco, nil
+}
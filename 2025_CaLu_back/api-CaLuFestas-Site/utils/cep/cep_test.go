@@ -0,0 +1,83 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/models"
+)
+
+func TestFixtures(t *testing.T) {
+	s := Fixtures()
+
+	endereco, err := s.Resolve(context.Background(), "01310-100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endereco.CEP != "01310100" || endereco.Logradouro != "Avenida Paulista" || endereco.UF != "SP" {
+		t.Fatalf("endereço = %+v", endereco)
+	}
+
+	if _, err := s.Resolve(context.Background(), "99999-999"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("erro = %v, esperado ErrNotFound", err)
+	}
+	if _, err := s.Resolve(context.Background(), "abc"); !errors.Is(err, models.ErrCEPInvalido) {
+		t.Fatalf("erro = %v, esperado ErrCEPInvalido", err)
+	}
+}
+
+func TestLoadStaticFile(t *testing.T) {
+	arquivo := filepath.Join(t.TempDir(), "ceps.json")
+	conteudo := `{"88010-001": {"logradouro": "Rua Felipe Schmidt", "bairro": "Centro", "cidade": "Florianópolis", "uf": "SC"}}`
+	if err := os.WriteFile(arquivo, []byte(conteudo), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadStaticFile(arquivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endereco, err := s.Resolve(context.Background(), "88010001"); err != nil || endereco.Cidade != "Florianópolis" {
+		t.Fatalf("endereço = %+v, erro = %v", endereco, err)
+	}
+
+	if _, err := LoadStatic(strings.NewReader(`{"123": {}}`)); err == nil {
+		t.Fatal("CEP inválido no arquivo foi aceito")
+	}
+}
+
+func TestViaCEP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/01001000/json/":
+			w.Write([]byte(`{"cep": "01001-000", "logradouro": "Praça da Sé", "bairro": "Sé", "localidade": "São Paulo", "uf": "SP"}`))
+		case "/99999999/json/":
+			w.Write([]byte(`{"erro": "true"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	v := NewViaCEP(srv.URL + "/")
+
+	endereco, err := v.Resolve(context.Background(), "01001-000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endereco.CEP != "01001000" || endereco.Cidade != "São Paulo" {
+		t.Fatalf("endereço = %+v", endereco)
+	}
+
+	if _, err := v.Resolve(context.Background(), "99999-999"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("erro = %v, esperado ErrNotFound", err)
+	}
+	if _, err := v.Resolve(context.Background(), "11111-111"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("erro = %v, esperada falha do serviço", err)
+	}
+}
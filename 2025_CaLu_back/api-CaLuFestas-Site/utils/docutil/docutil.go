@@ -0,0 +1,104 @@
+// Package docutil valida documentos e telefones brasileiros.
+package docutil
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	TipoCPF  = "cpf"
+	TipoCNPJ = "cnpj"
+)
+
+var (
+	ErrDocumentoInvalido = errors.New("CPF ou CNPJ inválido")
+	ErrTelefoneInvalido  = errors.New("telefone inválido")
+)
+
+// Digits remove tudo que não for dígito, de modo que "123.456.789-09" e
+// "12345678909" sejam o mesmo documento.
+func Digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Documento valida um CPF ou CNPJ, com ou sem máscara, e devolve só os
+// dígitos junto com o tipo.
+func Documento(doc string) (digitos, tipo string, err error) {
+	digitos = Digits(doc)
+	switch {
+	case len(digitos) == 11 && ValidCPF(digitos):
+		return digitos, TipoCPF, nil
+	case len(digitos) == 14 && ValidCNPJ(digitos):
+		return digitos, TipoCNPJ, nil
+	}
+	return "", "", ErrDocumentoInvalido
+}
+
+// ValidCPF confere os dígitos verificadores de um CPF sem máscara.
+func ValidCPF(cpf string) bool {
+	if len(cpf) != 11 || repetido(cpf) {
+		return false
+	}
+	return digitoVerificador(cpf[:9], pesosDecrescentes(10)) == cpf[9] &&
+		digitoVerificador(cpf[:10], pesosDecrescentes(11)) == cpf[10]
+}
+
+// ValidCNPJ confere os dígitos verificadores de um CNPJ sem máscara.
+func ValidCNPJ(cnpj string) bool {
+	if len(cnpj) != 14 || repetido(cnpj) {
+		return false
+	}
+	primeiro := []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	segundo := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	return digitoVerificador(cnpj[:12], primeiro) == cnpj[12] &&
+		digitoVerificador(cnpj[:13], segundo) == cnpj[13]
+}
+
+// Telefone aceita números com DDD, fixos (10 dígitos) ou celulares (11
+// dígitos), e devolve só os dígitos.
+func Telefone(tel string) (string, error) {
+	digitos := Digits(tel)
+	if len(digitos) != 10 && len(digitos) != 11 {
+		return "", ErrTelefoneInvalido
+	}
+	if digitos[0] == '0' || digitos[1] == '0' {
+		return "", ErrTelefoneInvalido
+	}
+	if len(digitos) == 11 && digitos[2] != '9' {
+		return "", ErrTelefoneInvalido
+	}
+	return digitos, nil
+}
+
+func pesosDecrescentes(inicio int) []int {
+	pesos := make([]int, inicio-1)
+	for i := range pesos {
+		pesos[i] = inicio - i
+	}
+	return pesos
+}
+
+func digitoVerificador(base string, pesos []int) byte {
+	soma := 0
+	for i, r := range base {
+		soma += int(r-'0') * pesos[i]
+	}
+	resto := soma % 11
+	if resto < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - resto)
+}
+
+// repetido rejeita sequências como 111.111.111-11, que passam no cálculo
+// dos dígitos mas não são documentos válidos.
+func repetido(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
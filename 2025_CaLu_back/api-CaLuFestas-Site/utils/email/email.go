@@ -2,6 +2,7 @@
 
 	import (
 		"fmt"
+		"strings"
 
 		"gopkg.in/gomail.v2"
 	)
@@ -43,3 +44,24 @@
 		m.SetBody("text/plain", fmt.Sprintf("Your password reset token is: %s", token))
 		return s.dialer.DialAndSend(m)
 	}
+
+	// Configured informa quais dados do SMTP estão faltando, se algum.
+	func (s *Sender) Configured() error {
+		var faltando []string
+		if s.dialer.Host == "" {
+			faltando = append(faltando, "SMTP_HOST")
+		}
+		if s.dialer.Port == 0 {
+			faltando = append(faltando, "SMTP_PORT")
+		}
+		if s.dialer.Username == "" {
+			faltando = append(faltando, "SMTP_EMAIL")
+		}
+		if s.dialer.Password == "" {
+			faltando = append(faltando, "SMTP_PSW")
+		}
+		if len(faltando) > 0 {
+			return fmt.Errorf("SMTP não configurado: %s", strings.Join(faltando, ", "))
+		}
+		return nil
+	}
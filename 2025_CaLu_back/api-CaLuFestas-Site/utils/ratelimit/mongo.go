@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoStore compartilha os contadores entre instâncias da API. A coleção
+// precisa de um índice TTL em "expira_em" para descartar janelas vencidas.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+var _ Store = (*MongoStore)(nil)
+
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+// Tentativas de upsert quando duas requisições criam a mesma janela ao mesmo
+// tempo; a perdedora recebe erro de chave duplicada e só precisa repetir
+const tentativasUpsert = 3
+
+func (s *MongoStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	// Cada janela é um documento próprio, identificado pelo início da janela
+	now := time.Now().UTC()
+	start := now.Truncate(window)
+	resetAt := start.Add(window)
+
+	var doc struct {
+		Count int `bson:"count"`
+	}
+	var err error
+	for tentativa := 0; tentativa < tentativasUpsert; tentativa++ {
+		err = s.collection.FindOneAndUpdate(ctx,
+			bson.M{"_id": key + "@" + start.Format(time.RFC3339)},
+			bson.M{
+				"$inc":         bson.M{"count": 1},
+				"$setOnInsert": bson.M{"key": key, "expira_em": resetAt},
+			},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&doc)
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return doc.Count, resetAt, nil
+}
+
+func (s *MongoStore) Reset(ctx context.Context, key string) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"key": key})
+	return err
+}
@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Psnsilvino/CaluFestas-Site-api/internal/mongotest"
+)
+
+func TestMongoStoreContaTentativasConcorrentes(t *testing.T) {
+	store := NewMongoStore(mongotest.Banco(t).Collection("rateLimits"))
+
+	// Todas disputam a criação do mesmo documento de janela
+	const tentativas = 20
+	var wg sync.WaitGroup
+	erros := make(chan error, tentativas)
+	for i := 0; i < tentativas; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := store.Hit(context.Background(), "login-ip:192.0.2.1", time.Hour); err != nil {
+				erros <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(erros)
+	for err := range erros {
+		t.Errorf("Hit falhou: %v", err)
+	}
+
+	count, _, err := store.Hit(context.Background(), "login-ip:192.0.2.1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != tentativas+1 {
+		t.Fatalf("contador = %d, esperado %d", count, tentativas+1)
+	}
+}
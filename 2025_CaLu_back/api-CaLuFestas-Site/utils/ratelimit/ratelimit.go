@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Store conta tentativas em janelas fixas de tempo.
+type Store interface {
+	// Hit registra uma tentativa para key na janela atual e devolve o total
+	// de tentativas na janela e quando ela termina.
+	Hit(ctx context.Context, key string, window time.Duration) (count int, resetAt time.Time, err error)
+	// Reset zera as tentativas de key.
+	Reset(ctx context.Context, key string) error
+}
+
+// Limiter permite até limit tentativas por chave a cada window.
+type Limiter struct {
+	store  Store
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewLimiter cria um limitador; prefix separa as chaves de limitadores que
+// compartilham o mesmo Store.
+func NewLimiter(store Store, prefix string, limit int, window time.Duration) *Limiter {
+	return &Limiter{store: store, prefix: prefix, limit: limit, window: window}
+}
+
+// Allow registra uma tentativa e informa se ela está dentro do limite. Quando
+// não está, retryAfter diz quanto falta para a janela reabrir.
+func (l *Limiter) Allow(ctx context.Context, key string) (ok bool, retryAfter time.Duration, err error) {
+	count, resetAt, err := l.store.Hit(ctx, l.prefix+":"+key, l.window)
+	if err != nil {
+		return true, 0, err
+	}
+	if count > l.limit {
+		return false, time.Until(resetAt), nil
+	}
+	return true, 0, nil
+}
+
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, l.prefix+":"+key)
+}
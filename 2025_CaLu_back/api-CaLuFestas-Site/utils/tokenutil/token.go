@@ -0,0 +1,98 @@
+package tokenutil
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// AccessTokenAudience separa o access token de outros JWTs assinados com o
+// mesmo segredo, como o quote_id das cotações.
+const AccessTokenAudience = "calufestas-access"
+
+// Claims do access token. Além das claims padrão (sub, iat, exp, jti) levamos
+// os dados que o front-end usa para montar o perfil.
+type Claims struct {
+	Nome  string `json:"nome"`
+	Email string `json:"email"`
+	Cargo string `json:"cargo"`
+	jwt.RegisteredClaims
+}
+
+func GenerateAccessToken(subject, nome, email, cargo string, secret []byte) (string, *Claims, error) {
+	jti, err := randomString(16)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now().UTC()
+	claims := &Claims{
+		Nome:  nome,
+		Email: email,
+		Cargo: cargo,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// ParseAccessToken valida assinatura, algoritmo, audiência e as datas do
+// token. Tokens sem exp ou de outra audiência são recusados.
+func ParseAccessToken(tokenString string, secret []byte) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(AccessTokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GenerateOpaqueToken gera tokens aleatórios de uso interno (refresh tokens,
+// redefinição de senha). Devolve o token entregue ao cliente e o hash que deve
+// ser guardado no banco; o token em si nunca é persistido.
+func GenerateOpaqueToken() (token string, hash string, err error) {
+	token, err = randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,53 @@
+package tokenutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var segredo = []byte("segredo-dos-testes-com-mais-de-32-caracteres")
+
+func TestAccessTokenValido(t *testing.T) {
+	token, _, err := GenerateAccessToken("id-do-cliente", "Ana", "ana@calu.com", "cliente", segredo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParseAccessToken(token, segredo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "id-do-cliente" || claims.Email != "ana@calu.com" {
+		t.Fatalf("claims = %+v", claims)
+	}
+}
+
+func TestParseAccessTokenExigeAudiencia(t *testing.T) {
+	agora := time.Now()
+	for nome, audiencia := range map[string]jwt.ClaimStrings{
+		"sem audiência":   nil,
+		"outra audiência": {"cotacao"},
+	} {
+		t.Run(nome, func(t *testing.T) {
+			// Mesmo segredo e claims de um access token, como um quote_id
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+				Email: "ana@calu.com",
+				RegisteredClaims: jwt.RegisteredClaims{
+					Subject:   "id-do-cliente",
+					Audience:  audiencia,
+					IssuedAt:  jwt.NewNumericDate(agora),
+					ExpiresAt: jwt.NewNumericDate(agora.Add(time.Minute)),
+				},
+			}).SignedString(segredo)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := ParseAccessToken(token, segredo); err == nil {
+				t.Fatal("token de outra audiência aceito como access token")
+			}
+		})
+	}
+}